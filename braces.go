@@ -1,6 +1,8 @@
 package braces
 
 import (
+	"context"
+
 	"github.com/pierre-primary/go-braces/syntax"
 )
 
@@ -14,6 +16,14 @@ func Walk(input string, handler WalkHandler) {
 	exp.Walk(handler)
 }
 
+func WalkContext(ctx context.Context, input string, handler WalkHandler) error {
+	exp, err := syntax.Parse(input)
+	if err != nil {
+		return err
+	}
+	return exp.WalkContext(ctx, handler)
+}
+
 func Expand(input string) []string {
 	exp, err := syntax.Parse(input)
 	if err != nil {
@@ -37,3 +47,35 @@ func PrintTree(input string) {
 	}
 	exp.Print()
 }
+
+type Equivalence = syntax.Equivalence
+
+const (
+	SeqEquivalence = syntax.SeqEquivalence
+	SetEquivalence = syntax.SetEquivalence
+)
+
+func Equivalent(a, b string, level Equivalence) bool {
+	x, err := syntax.Parse(a)
+	if err != nil {
+		panic(err)
+	}
+	y, err := syntax.Parse(b)
+	if err != nil {
+		panic(err)
+	}
+	return syntax.Equivalent(x, y, level)
+}
+
+func Compress(strs []string) string {
+	return syntax.Compress(strs).String()
+}
+
+func SplitBase(input string) (base, rest string) {
+	exp, err := syntax.Parse(input)
+	if err != nil {
+		panic(err)
+	}
+	base, r := syntax.SplitBase(exp)
+	return base, r.String()
+}
@@ -0,0 +1,294 @@
+// Package hostlist converts between lists of host names and the hostlist
+// notation of Slurm, pdsh and ClusterShell, such as node[001-128,200],gpu[1-4].
+package hostlist
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pierre-primary/go-braces/syntax"
+)
+
+// Error reports a malformed hostlist and the offset where it was found.
+type Error struct {
+	Msg string
+	Pos int
+}
+
+func (e *Error) Error() string {
+	return "hostlist: " + e.Msg + " at " + strconv.Itoa(e.Pos)
+}
+
+var (
+	ErrUnmatchedBracket = "unmatched bracket"
+	ErrEmptyBracket     = "empty bracket item"
+	ErrBadRange         = "bad range"
+)
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isPadded reports whether s is a number written with leading zeros.
+func isPadded(s string) bool {
+	return len(s) > 1 && s[0] == '0'
+}
+
+// number reads the digits of s at i and returns them with their end.
+func number(s string, i int) (digits string, end int) {
+	end = i
+	for end < len(s) && isDigit(s[end]) {
+		end++
+	}
+	return s[i:end], end
+}
+
+// parseItem parses one item of a bracket: a number, lo-hi or lo-hi/step.
+// A range is padded to the width of lo when lo has leading zeros.
+func parseItem(s string, pos int) (*syntax.BraceExp, error) {
+	if s == "" {
+		return nil, &Error{ErrEmptyBracket, pos}
+	}
+	lo, i := number(s, 0)
+	if lo == "" {
+		return nil, &Error{ErrBadRange, pos}
+	}
+	if i == len(s) {
+		return syntax.NewLiteral(lo), nil
+	}
+	if s[i] != '-' {
+		return nil, &Error{ErrBadRange, pos + i}
+	}
+	hi, j := number(s, i+1)
+	step := "1"
+	if j < len(s) && s[j] == '/' {
+		step, j = number(s, j+1)
+	}
+	if hi == "" || step == "" || j != len(s) {
+		return nil, &Error{ErrBadRange, pos + j}
+	}
+	a, err1 := strconv.Atoi(lo)
+	b, err2 := strconv.Atoi(hi)
+	n, err3 := strconv.Atoi(step)
+	if err1 != nil || err2 != nil || err3 != nil || a > b || n == 0 {
+		return nil, &Error{ErrBadRange, pos}
+	}
+	wid := 0
+	if isPadded(lo) {
+		wid = len(lo)
+	}
+	return syntax.NewIntegerRange(a, b, n, wid), nil
+}
+
+// parseHost parses a host pattern, which may hold several brackets.
+func parseHost(s string, pos int) (*syntax.BraceExp, error) {
+	var subs []*syntax.BraceExp
+	for len(s) > 0 {
+		i := strings.IndexAny(s, "[]")
+		if i < 0 {
+			subs = append(subs, syntax.NewLiteral(s))
+			break
+		}
+		if s[i] == ']' {
+			return nil, &Error{ErrUnmatchedBracket, pos + i}
+		}
+		if i > 0 {
+			subs = append(subs, syntax.NewLiteral(s[:i]))
+		}
+		j := strings.IndexAny(s[i+1:], "[]")
+		if j < 0 || s[i+1+j] != ']' {
+			return nil, &Error{ErrUnmatchedBracket, pos + i}
+		}
+		var items []*syntax.BraceExp
+		start := i + 1
+		for _, item := range strings.Split(s[i+1:i+1+j], ",") {
+			exp, err := parseItem(item, pos+start)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, exp)
+			start += len(item) + 1
+		}
+		subs = append(subs, syntax.NewAlternate(items...))
+		s, pos = s[i+2+j:], pos+i+2+j
+	}
+	return syntax.NewConcat(subs...), nil
+}
+
+// Parse parses a hostlist into a brace expression. Hosts are separated by
+// commas outside of brackets and empty hosts are skipped. A bracket holds
+// numbers and lo-hi or lo-hi/step ranges, and a host may hold several
+// brackets, which expand to their product with the last one turning fastest.
+// Parse returns nil for a list without hosts.
+func Parse(hostlist string) (*syntax.BraceExp, error) {
+	var hosts []*syntax.BraceExp
+	depth, sta := 0, 0
+	for i := 0; i <= len(hostlist); i++ {
+		if i < len(hostlist) {
+			switch hostlist[i] {
+			case '[':
+				depth++
+				continue
+			case ']':
+				depth--
+				continue
+			case ',':
+				if depth > 0 {
+					continue
+				}
+			default:
+				continue
+			}
+		}
+		if sta < i {
+			host, err := parseHost(hostlist[sta:i], sta)
+			if err != nil {
+				return nil, err
+			}
+			hosts = append(hosts, host)
+		}
+		sta = i + 1
+	}
+	if len(hosts) == 0 {
+		return nil, nil
+	}
+	return syntax.NewAlternate(hosts...), nil
+}
+
+// Expand returns the host names of a hostlist in order.
+func Expand(hostlist string) ([]string, error) {
+	exp, err := Parse(hostlist)
+	if exp == nil {
+		return nil, err
+	}
+	return exp.Expand(nil), nil
+}
+
+// split cuts a host name into the text around its numbers and the numbers.
+// Numbers too large for an int are kept in the text.
+func split(host string) (text []string, nums []string) {
+	sta := 0
+	for i := 0; i < len(host); {
+		if !isDigit(host[i]) {
+			i++
+			continue
+		}
+		digits, end := number(host, i)
+		if _, err := strconv.Atoi(digits); err == nil {
+			text = append(text, host[sta:i])
+			nums = append(nums, digits)
+			sta = end
+		}
+		i = end
+	}
+	return append(text, host[sta:]), nums
+}
+
+// foldNumbers folds numbers into the items of a bracket. Consecutive numbers
+// join a range when they print the same under its padding.
+func foldNumbers(nums []string) string {
+	vals := make(map[string]int, len(nums))
+	for _, s := range nums {
+		vals[s], _ = strconv.Atoi(s)
+	}
+	nums = slices.SortedFunc(maps.Keys(vals), func(a, b string) int {
+		if vals[a] != vals[b] {
+			return vals[a] - vals[b]
+		}
+		if len(a) != len(b) {
+			return len(a) - len(b)
+		}
+		return strings.Compare(a, b)
+	})
+
+	var buf []byte
+	for i := 0; i < len(nums); {
+		wid := 0
+		if isPadded(nums[i]) {
+			wid = len(nums[i])
+		}
+		j := i + 1
+		for j < len(nums) && vals[nums[j]] == vals[nums[j-1]]+1 {
+			// a padded number only fits a range of its own width, and an
+			// unpadded one any range it is not shorter than
+			if s := nums[j]; (isPadded(s) && len(s) != wid) || (!isPadded(s) && len(s) < wid) {
+				break
+			}
+			j++
+		}
+		if len(buf) > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, nums[i]...)
+		if j-i > 1 {
+			buf = append(buf, '-')
+			buf = append(buf, nums[j-1]...)
+		}
+		i = j
+	}
+	return string(buf)
+}
+
+// Fold returns the canonical hostlist of hosts: host names are grouped by
+// the text around their numbers, and the numbers of each group are folded
+// from the last one to the first, so that a product of brackets is found.
+// Duplicates are removed and the result is sorted.
+func Fold(hosts []string) string {
+	type group struct {
+		text []string
+		rows [][]string
+	}
+	groups := map[string]*group{}
+	for _, host := range hosts {
+		text, nums := split(host)
+		key := strings.Join(text, "\x00")
+		g := groups[key]
+		if g == nil {
+			g = &group{text: text}
+			groups[key] = g
+		}
+		g.rows = append(g.rows, nums)
+	}
+
+	var out []string
+	for _, g := range groups {
+		rows := g.rows
+		for d := len(g.text) - 2; d >= 0; d-- {
+			// fold column d of the rows that agree on every other column
+			var keys []string
+			cols := map[string][]string{}
+			first := map[string][]string{}
+			for _, row := range rows {
+				key := strings.Join(row[:d], "\x00") + "\x01" + strings.Join(row[d+1:], "\x00")
+				if _, ok := cols[key]; !ok {
+					keys = append(keys, key)
+					first[key] = row
+				}
+				cols[key] = append(cols[key], row[d])
+			}
+			rows = rows[:0:0]
+			for _, key := range keys {
+				row := slices.Clone(first[key])
+				row[d] = foldNumbers(cols[key])
+				rows = append(rows, row)
+			}
+		}
+		for _, row := range rows {
+			var b strings.Builder
+			for i, nums := range row {
+				b.WriteString(g.text[i])
+				if strings.ContainsAny(nums, ",-") {
+					b.WriteString("[" + nums + "]")
+				} else {
+					b.WriteString(nums)
+				}
+			}
+			b.WriteString(g.text[len(g.text)-1])
+			out = append(out, b.String())
+		}
+	}
+	slices.Sort(out)
+	return strings.Join(slices.Compact(out), ",")
+}
@@ -0,0 +1,72 @@
+package hostlist_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pierre-primary/go-braces/hostlist"
+)
+
+type E = []string
+
+func TestExpand(t *testing.T) {
+	equal := func(input string, expected []string) {
+		result, err := hostlist.Expand(input)
+		if err != nil {
+			t.Fatal(input, err)
+		}
+		if !slices.Equal(result, expected) {
+			t.Fatal(input, result)
+		}
+	}
+	fail := func(input string, msg string) {
+		_, err := hostlist.Expand(input)
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Fatal(input, err)
+		}
+	}
+
+	equal("node[001-003,200],gpu[1-2]", E{"node001", "node002", "node003", "node200", "gpu1", "gpu2"})
+	equal("n[08-10]", E{"n08", "n09", "n10"})
+	equal("n[1-7/3]", E{"n1", "n4", "n7"})
+	equal("rack[1-2]-n[01-02]", E{"rack1-n01", "rack1-n02", "rack2-n01", "rack2-n02"})
+	equal("login,,n[5]x", E{"login", "n5x"})
+	equal("", nil)
+
+	fail("n[1-2", hostlist.ErrUnmatchedBracket)
+	fail("n1-2]", hostlist.ErrUnmatchedBracket)
+	fail("n[[1]]", hostlist.ErrUnmatchedBracket)
+	fail("n[1,,2]", hostlist.ErrEmptyBracket)
+	fail("n[3-1]", hostlist.ErrBadRange)
+	fail("n[a-c]", hostlist.ErrBadRange)
+	fail("n[1-3/0]", hostlist.ErrBadRange)
+}
+
+func TestFold(t *testing.T) {
+	equal := func(hosts []string, expected string) {
+		if result := hostlist.Fold(hosts); result != expected {
+			t.Fatal(hosts, result)
+		}
+	}
+
+	equal(E{"node3", "node1", "node2", "node5", "gpu1"}, "gpu1,node[1-3,5]")
+	equal(E{"n09", "n10", "n08", "n99", "n100"}, "n[08-10,99-100]")
+	equal(E{"n9", "n10", "n010"}, "n[9-10,010]")
+	equal(E{"n1", "n01"}, "n[1,01]")
+	equal(E{"rack1-n01", "rack1-n02", "rack2-n01", "rack2-n02", "rack3-n01"}, "rack3-n01,rack[1-2]-n[01-02]")
+	equal(E{"login", "login", "n7"}, "login,n7")
+	equal(nil, "")
+
+	// folding and expanding give back the same hosts
+	hosts, _ := hostlist.Expand("a[1-3]b[07-12],c[5-6,9],d")
+	again, err := hostlist.Expand(hostlist.Fold(hosts))
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(hosts)
+	slices.Sort(again)
+	if !slices.Equal(hosts, again) {
+		t.Fatal(again)
+	}
+}
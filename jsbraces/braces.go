@@ -0,0 +1,171 @@
+// Package jsbraces expands and compiles brace patterns like the braces
+// package of Node.js (micromatch/braces), so Go and JS tooling produce the
+// same results from the same patterns.
+package jsbraces
+
+import (
+	"errors"
+	"unicode/utf8"
+
+	"github.com/pierre-primary/go-braces/syntax"
+)
+
+var (
+	ErrRangeLimit = errors.New("jsbraces: expanded array length exceeds range limit")
+	ErrMaxLength  = errors.New("jsbraces: input length exceeds max characters")
+)
+
+const (
+	DefaultRangeLimit = 1000
+	DefaultMaxLength  = 1024 * 64
+)
+
+// Options mirrors the options of braces. A nil *Options uses the defaults.
+type Options struct {
+	// Expand makes Braces expand the pattern instead of compiling it.
+	Expand bool
+	// Nodupes removes duplicates from expansions.
+	Nodupes bool
+	// RangeLimit is the number of steps from which expanding an ascending
+	// integer range without a step is an error. Zero means DefaultRangeLimit, a negative value
+	// disables the limit.
+	RangeLimit int
+	// MaxLength is the length from which a pattern is rejected. Zero means
+	// DefaultMaxLength.
+	MaxLength int
+	// KeepEscaping keeps backslashes in the output.
+	KeepEscaping bool
+	// KeepQuotes keeps quotes in the output.
+	KeepQuotes bool
+}
+
+func parse(pattern string, opts *Options) (*syntax.BraceExp, syntax.ExpandFlags, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	maxLength := opts.MaxLength
+	if maxLength == 0 {
+		maxLength = DefaultMaxLength
+	}
+	if len(pattern) > maxLength {
+		return nil, 0, ErrMaxLength
+	}
+	exp, err := syntax.Parse(pattern, syntax.JSCompat)
+	if err != nil {
+		return nil, 0, err
+	}
+	var flags syntax.ExpandFlags
+	if opts.KeepEscaping {
+		flags |= syntax.KeepEscape
+	}
+	if opts.KeepQuotes {
+		flags |= syntax.KeepQuote
+	}
+	return exp, flags, nil
+}
+
+// exceedsLimit ports the check of braces, (max - min) / step >= limit. braces
+// passes a written step in place of the limit, so only ranges without a step
+// are limited, and descending ones never are. A written step of 1 cannot be
+// told from none.
+func exceedsLimit(exp *syntax.BraceExp, limit int) bool {
+	switch exp.Op {
+	case syntax.OpIntegerRange:
+		_, num, sep, _ := exp.Range()
+		return (sep == 1 || sep == -1) && num*sep >= limit
+	case syntax.OpConcat, syntax.OpAlternate:
+		for _, sub := range exp.Subs {
+			if exceedsLimit(sub, limit) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Expand returns every string the pattern expands to, like braces.expand.
+func Expand(pattern string, opts *Options) ([]string, error) {
+	exp, flags, err := parse(pattern, opts)
+	if err != nil {
+		return nil, err
+	}
+	limit := DefaultRangeLimit
+	if opts != nil && opts.RangeLimit != 0 {
+		limit = opts.RangeLimit
+	}
+	if limit > 0 && exceedsLimit(exp, limit) {
+		return nil, ErrRangeLimit
+	}
+	if opts != nil && opts.Nodupes {
+		flags |= syntax.Unique
+	}
+	return exp.Expand(nil, flags), nil
+}
+
+func appendText(buf []byte, exp *syntax.BraceExp, flags syntax.ExpandFlags) []byte {
+	switch exp.Op {
+	case syntax.OpEscape:
+		if flags&syntax.KeepEscape == 0 {
+			return append(buf, exp.Val[1:]...)
+		}
+	case syntax.OpQuote:
+		if flags&syntax.KeepQuote == 0 {
+			return buf
+		}
+	case syntax.OpEmpty:
+		return buf
+	}
+	return append(buf, exp.Val...)
+}
+
+func compile(buf []byte, exp *syntax.BraceExp, flags syntax.ExpandFlags) []byte {
+	switch exp.Op {
+	case syntax.OpConcat:
+		for _, sub := range exp.Subs {
+			buf = compile(buf, sub, flags)
+		}
+		return buf
+	case syntax.OpAlternate:
+		buf = append(buf, '(')
+		for i, sub := range exp.Subs {
+			// braces drops the separator after an empty item that follows
+			// another separator
+			if i == 1 || (i > 1 && exp.Subs[i-1].Op != syntax.OpEmpty) {
+				buf = append(buf, '|')
+			}
+			buf = compile(buf, sub, flags)
+		}
+		return append(buf, ')')
+	case syntax.OpIntegerRange, syntax.OpCharRange:
+		str := rangeRegex(exp)
+		if utf8.RuneCountInString(str) > 1 {
+			return append(append(append(buf, '('), str...), ')')
+		}
+		return append(buf, str...)
+	default:
+		return appendText(buf, exp, flags)
+	}
+}
+
+// Compile returns the pattern as a regular expression source, like braces
+// without the expand option: lists become groups and ranges become
+// character classes or alternations. Text is copied as it is.
+func Compile(pattern string, opts *Options) (string, error) {
+	exp, flags, err := parse(pattern, opts)
+	if err != nil {
+		return "", err
+	}
+	return string(compile(nil, exp, flags)), nil
+}
+
+// Braces compiles the pattern, or expands it if opts.Expand is set.
+func Braces(pattern string, opts *Options) ([]string, error) {
+	if opts != nil && opts.Expand {
+		return Expand(pattern, opts)
+	}
+	str, err := Compile(pattern, opts)
+	if err != nil {
+		return nil, err
+	}
+	return []string{str}, nil
+}
@@ -0,0 +1,120 @@
+package jsbraces_test
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/pierre-primary/go-braces/jsbraces"
+)
+
+type E = []string
+
+func TestExpand(t *testing.T) {
+	equal := func(pattern string, opts *jsbraces.Options, expected []string) {
+		result, err := jsbraces.Expand(pattern, opts)
+		if err != nil {
+			t.Fatal(pattern, err)
+		}
+		if !slices.Equal(result, expected) {
+			t.Fatal(pattern, result)
+		}
+	}
+
+	equal("a/{x,y,z}/b", nil, E{"a/x/b", "a/y/b", "a/z/b"})
+	equal("{a..e..2}", nil, E{"a", "c", "e"})
+	equal("{a..z..10}", nil, E{"a", "k", "u"})
+	equal("{Z..a..3}", nil, E{"Z", "]", "`"})
+	equal("{01..100..33}", nil, E{"001", "034", "067", "100"})
+	equal("{1..10..03}", nil, E{"01", "04", "07", "10"})
+	equal("{-1..02}", nil, E{"-1", "00", "01", "02"})
+	equal(`a\{b,c}`, nil, E{"a{b,c}"})
+	equal(`a\{b,c}`, &jsbraces.Options{KeepEscaping: true}, E{`a\{b,c}`})
+	equal("{a,b,a}", nil, E{"a", "b", "a"})
+	equal("{a,b,a}", &jsbraces.Options{Nodupes: true}, E{"a", "b"})
+	equal("[a,b]{c,d}", nil, E{"[a,b]c", "[a,b]d"})
+	equal("${a,b}{c,d}", nil, E{"${a,b}c", "${a,b}d"})
+	equal("`a,b`{c,d}", nil, E{"a,bc", "a,bd"})
+	equal(`{"a\"b",c}`, nil, E{`a\"b`, "c"})
+	equal(`{"a\"b",c}`, &jsbraces.Options{KeepQuotes: true}, E{`"a\"b"`, "c"})
+	equal("{}", nil, E{"{}"})
+	equal("{a}", nil, E{"{a}"})
+}
+
+func TestRangeLimit(t *testing.T) {
+	if _, err := jsbraces.Expand("{1..1001}", nil); err != jsbraces.ErrRangeLimit {
+		t.Fatal(err)
+	}
+	if result, err := jsbraces.Expand("{1..1000}", nil); err != nil || len(result) != 1000 {
+		t.Fatal(err)
+	}
+	if result, err := jsbraces.Expand("{1..1001}", &jsbraces.Options{RangeLimit: -1}); err != nil || len(result) != 1001 {
+		t.Fatal(err)
+	}
+	if _, err := jsbraces.Expand("x{a,{1..20}}", &jsbraces.Options{RangeLimit: 10}); err != jsbraces.ErrRangeLimit {
+		t.Fatal(err)
+	}
+	// descending ranges and ranges with a step are never limited
+	if result, err := jsbraces.Expand("{1001..1}", nil); err != nil || len(result) != 1001 {
+		t.Fatal(err)
+	}
+	if result, err := jsbraces.Expand("{1..2001..2}", nil); err != nil || len(result) != 1001 {
+		t.Fatal(err)
+	}
+	// compiling does not expand ranges
+	if result, err := jsbraces.Braces("{1..100000}", nil); err != nil || len(result) != 1 {
+		t.Fatal(err)
+	}
+	if _, err := jsbraces.Expand("{a,b}", &jsbraces.Options{MaxLength: 3}); err != jsbraces.ErrMaxLength {
+		t.Fatal(err)
+	}
+}
+
+func TestCompile(t *testing.T) {
+	equal := func(pattern string, expected string) {
+		result, err := jsbraces.Compile(pattern, nil)
+		if err != nil {
+			t.Fatal(pattern, err)
+		}
+		if result != expected {
+			t.Fatal(pattern, result)
+		}
+	}
+
+	equal("a/{x,y,z}/b", "a/(x|y|z)/b")
+	equal("a{b,c{d,e}}", "a(b|c(d|e))")
+	equal("{a,,b}", "(a|b)")
+	equal("{,a}", "(|a)")
+	equal("{a,}", "(a|)")
+	equal("a{b}c", "a{b}c")
+	equal("{1..5}", "([1-5])")
+	equal("{5..1}", "([1-5])")
+	equal("{1..2}", "(1|2)")
+	equal("{3..3}", "3")
+	equal("{1..10..3}", "(1|4|7|10)")
+	equal("{-4..4..4}", "(0|4|-(?:4))")
+	equal("{a..e}", "([a-e])")
+	equal("{a..e..2}", "(a|c|e)")
+	equal("{1..100}", "([1-9]|[1-9][0-9]|100)")
+	equal("{-10..10}", "(-[1-9]|-?10|[0-9])")
+	equal("{001..100}", "(0{0,2}[1-9]|0?[1-9][0-9]|100)")
+	equal("{1..1000000}", "([1-9]|[1-9][0-9]{1,5}|1000000)")
+}
+
+// The compiled regexp matches exactly the expansion of the ranges.
+func TestCompileRanges(t *testing.T) {
+	for _, r := range [][2]int{{0, 9}, {1, 99}, {7, 1234}, {-25, 40}, {-300, -11}, {99, 1001}, {10, 19}} {
+		pattern := "{" + strconv.Itoa(r[0]) + ".." + strconv.Itoa(r[1]) + "}"
+		source, err := jsbraces.Compile(pattern, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		re := regexp.MustCompile("^(?:" + source + ")$")
+		for i := r[0] - 50; i <= r[1]+50; i++ {
+			if re.MatchString(strconv.Itoa(i)) != (i >= r[0] && i <= r[1]) {
+				t.Fatal(pattern, source, i)
+			}
+		}
+	}
+}
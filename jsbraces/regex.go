@@ -0,0 +1,283 @@
+package jsbraces
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pierre-primary/go-braces/syntax"
+)
+
+// This file follows fill-range and to-regex-range, which braces uses to
+// compile ranges.
+
+func appendNumber(buf []byte, val, wid int) []byte {
+	if val < 0 {
+		buf = append(buf, '-')
+		wid--
+	}
+	s := strconv.FormatUint(uint64(absInt(val)), 10)
+	for i := len(s); i < wid; i++ {
+		buf = append(buf, '0')
+	}
+	return append(buf, s...)
+}
+
+func absInt(x int) uint {
+	if x < 0 {
+		return uint(-x)
+	}
+	return uint(x)
+}
+
+func hasPadding(s string) bool {
+	s = strings.TrimPrefix(s, "-")
+	return len(s) >= 2 && s[0] == '0' && s[1] >= '0' && s[1] <= '9'
+}
+
+// rangeRegex compiles a range like fill-range with the toRegex option.
+func rangeRegex(exp *syntax.BraceExp) string {
+	sta, num, sep, wid := exp.Range()
+	end := sta + num*sep
+	if exp.Op == syntax.OpCharRange {
+		if num == 0 || sep == 1 || sep == -1 {
+			lo, hi := min(sta, end), max(sta, end)
+			if lo == hi {
+				return string(rune(lo))
+			}
+			return "[" + string(rune(lo)) + "-" + string(rune(hi)) + "]"
+		}
+		var buf []byte
+		for i := 0; i <= num; i++ {
+			if i > 0 {
+				buf = append(buf, '|')
+			}
+			buf = utf8.AppendRune(buf, rune(sta+i*sep))
+		}
+		return string(buf)
+	}
+
+	if num == 0 || sep == 1 || sep == -1 {
+		return toRegexRange(string(appendNumber(nil, sta, wid)), string(appendNumber(nil, end, wid)))
+	}
+	// a sequence of the items, positives first
+	var pos, neg []uint
+	for i := 0; i <= num; i++ {
+		if v := sta + i*sep; v < 0 {
+			neg = append(neg, absInt(v))
+		} else {
+			pos = append(pos, uint(v))
+		}
+	}
+	join := func(vals []uint) string {
+		slices.Sort(vals)
+		var buf []byte
+		for i, v := range vals {
+			if i > 0 {
+				buf = append(buf, '|')
+			}
+			buf = appendNumber(buf, int(v), wid)
+		}
+		return string(buf)
+	}
+	switch {
+	case len(neg) == 0:
+		return join(pos)
+	case len(pos) == 0:
+		return "-(?:" + join(neg) + ")"
+	default:
+		return join(pos) + "|-(?:" + join(neg) + ")"
+	}
+}
+
+type token struct {
+	pattern string
+	count   []int
+	string  string
+}
+
+type regexState struct {
+	padded bool
+	maxLen int
+}
+
+func toRegexRange(minStr, maxStr string) string {
+	if minStr == maxStr {
+		return minStr
+	}
+	x, _ := strconv.Atoi(minStr)
+	y, _ := strconv.Atoi(maxStr)
+	a, b := min(x, y), max(x, y)
+	if b-a == 1 {
+		return minStr + "|" + maxStr
+	}
+
+	var st regexState
+	if hasPadding(minStr) || hasPadding(maxStr) {
+		st.padded, st.maxLen = true, len(maxStr)
+	}
+	var pos, neg []*token
+	if a < 0 {
+		newMin := 1
+		if b < 0 {
+			newMin = -b
+		}
+		neg = splitToPatterns(newMin, int(absInt(a)), st)
+		a = 0
+	}
+	if b >= 0 {
+		pos = splitToPatterns(a, b, st)
+	}
+	return collatePatterns(neg, pos)
+}
+
+func collatePatterns(neg, pos []*token) string {
+	var subs []string
+	subs = filterPatterns(subs, neg, pos, "-", false)
+	subs = filterPatterns(subs, neg, pos, "-?", true)
+	subs = filterPatterns(subs, pos, neg, "", false)
+	return strings.Join(subs, "|")
+}
+
+func filterPatterns(subs []string, arr, comparison []*token, prefix string, intersection bool) []string {
+	for _, ele := range arr {
+		contains := slices.ContainsFunc(comparison, func(t *token) bool { return t.string == ele.string })
+		if contains == intersection {
+			subs = append(subs, prefix+ele.string)
+		}
+	}
+	return subs
+}
+
+func countNines(min, n int) int {
+	s := strconv.Itoa(min)
+	s = s[:max(len(s)-n, 0)] + strings.Repeat("9", n)
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return math.MaxInt
+	}
+	return v
+}
+
+func countZeros(integer, zeros int) int {
+	if zeros > 18 {
+		return 0
+	}
+	pow := 1
+	for ; zeros > 0; zeros-- {
+		pow *= 10
+	}
+	return integer - integer%pow
+}
+
+func splitToRanges(min, max int) []int {
+	stops := []int{max}
+	add := func(stop int) {
+		if !slices.Contains(stops, stop) {
+			stops = append(stops, stop)
+		}
+	}
+	nines, zeros := 1, 1
+	for stop := countNines(min, nines); min <= stop && stop <= max && nines < 20; stop = countNines(min, nines) {
+		add(stop)
+		nines++
+	}
+	if max < math.MaxInt {
+		for stop := countZeros(max+1, zeros) - 1; min < stop && stop <= max; stop = countZeros(max+1, zeros) - 1 {
+			add(stop)
+			zeros++
+		}
+	}
+	slices.Sort(stops)
+	return stops
+}
+
+func rangeToPattern(start, stop string) *token {
+	if start == stop {
+		return &token{pattern: start}
+	}
+	var pattern []byte
+	count := 0
+	for i := 0; i < len(start) && i < len(stop); i++ {
+		a, b := start[i], stop[i]
+		switch {
+		case a == b:
+			pattern = append(pattern, a)
+		case a != '0' || b != '9':
+			pattern = append(pattern, '[', a)
+			if b-a != 1 {
+				pattern = append(pattern, '-')
+			}
+			pattern = append(pattern, b, ']')
+		default:
+			count++
+		}
+	}
+	if count > 0 {
+		pattern = append(pattern, "[0-9]"...)
+	}
+	return &token{pattern: string(pattern), count: []int{count}}
+}
+
+func toQuantifier(count []int) string {
+	start, stop := 0, 0
+	if len(count) > 0 {
+		start = count[0]
+	}
+	if len(count) > 1 {
+		stop = count[1]
+	}
+	if stop != 0 {
+		return "{" + strconv.Itoa(start) + "," + strconv.Itoa(stop) + "}"
+	}
+	if start > 1 {
+		return "{" + strconv.Itoa(start) + "}"
+	}
+	return ""
+}
+
+func padZeros(value int, st regexState) string {
+	diff := st.maxLen - len(strconv.Itoa(value))
+	if diff < 0 {
+		diff = -diff
+	}
+	switch diff {
+	case 0:
+		return ""
+	case 1:
+		return "0?"
+	default:
+		return "0{0," + strconv.Itoa(diff) + "}"
+	}
+}
+
+func splitToPatterns(min, max int, st regexState) []*token {
+	var tokens []*token
+	var prev *token
+	start := min
+	for _, stop := range splitToRanges(min, max) {
+		obj := rangeToPattern(strconv.Itoa(start), strconv.Itoa(stop))
+		if !st.padded && prev != nil && prev.pattern == obj.pattern {
+			if len(prev.count) > 1 {
+				prev.count = prev.count[:len(prev.count)-1]
+			}
+			if len(obj.count) > 0 {
+				prev.count = append(prev.count, obj.count[0])
+			}
+			prev.string = prev.pattern + toQuantifier(prev.count)
+			start = stop + 1
+			continue
+		}
+		zeros := ""
+		if st.padded {
+			zeros = padZeros(stop, st)
+		}
+		obj.string = zeros + obj.pattern + toQuantifier(obj.count)
+		tokens = append(tokens, obj)
+		start = stop + 1
+		prev = obj
+	}
+	return tokens
+}
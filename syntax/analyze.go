@@ -0,0 +1,233 @@
+package syntax
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+func intLen(val, wid int, f numFormat) int {
+	n := 1 + len(f.prefix())
+	u, base := absToUint(val), f.base()
+	for u >= base {
+		u /= base
+		n++
+	}
+	if val < 0 {
+		n++
+	}
+	if n < wid {
+		return wid
+	}
+	return n
+}
+
+// intRangeLens returns the shortest and the longest item of an integer range.
+// Lengths grow with the magnitude on both sides of zero, so only the bounds
+// and the items closest to zero need to be looked at.
+func intRangeLens(sta, num, sep, wid int, f numFormat) (min, max int) {
+	lo, hi, step := sta, sta+num*sep, int(absToUint(sep))
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	min, max = intLen(lo, wid, f), intLen(hi, wid, f)
+	if min > max {
+		min, max = max, min
+	}
+	if lo < 0 && hi >= 0 && step > 0 {
+		k := (-lo + step - 1) / step
+		for _, v := range [2]int{lo + (k-1)*step, lo + k*step} {
+			if l := intLen(v, wid, f); l < min {
+				min = l
+			}
+		}
+	}
+	return min, max
+}
+
+// lenBounds returns the shortest and the longest length of the strings
+// produced by exp.
+func lenBounds(exp *BraceExp, flags ExpandFlags) (min, max int) {
+	switch exp.Op {
+	case OpIntegerRange:
+		sta, num, sep, wid := rangeData(exp)
+		return intRangeLens(sta, num, sep, wid, rangeFormat(exp))
+	case OpCharRange:
+		sta, num, sep, _ := rangeData(exp)
+		min, max = utf8.RuneLen(rune(sta)), utf8.RuneLen(rune(sta+num*sep))
+		if min > max {
+			min, max = max, min
+		}
+		return min, max
+	case OpConcat:
+		for _, sub := range exp.Subs {
+			lo, hi := lenBounds(sub, flags)
+			min, max = min+lo, max+hi
+		}
+		return min, max
+	case OpAlternate:
+		for i, sub := range exp.Subs {
+			lo, hi := lenBounds(sub, flags)
+			if i == 0 || lo < min {
+				min = lo
+			}
+			if i == 0 || hi > max {
+				max = hi
+			}
+		}
+		return min, max
+	default:
+		min = len(appendConst(nil, exp, flags))
+		return min, min
+	}
+}
+
+func fixedLen(exp *BraceExp, flags ExpandFlags) bool {
+	min, max := lenBounds(exp, flags)
+	return min == max
+}
+
+// runePrefix shortens the common prefix of length n of a and b so that it
+// does not end inside a UTF-8 sequence.
+func runePrefix(a, b []byte, n int) int {
+	for n > 0 && (n < len(a) && !utf8.RuneStart(a[n]) || n < len(b) && !utf8.RuneStart(b[n])) {
+		n--
+	}
+	return n
+}
+
+// head returns a prefix shared by all strings produced by exp. complete is
+// set when exp only produces that string.
+func head(exp *BraceExp, flags ExpandFlags) (prefix []byte, complete bool) {
+	switch exp.Op {
+	case OpConcat:
+		for _, sub := range exp.Subs {
+			var h []byte
+			h, complete = head(sub, flags)
+			prefix = append(prefix, h...)
+			if !complete {
+				break
+			}
+		}
+		return prefix, complete
+	case OpAlternate:
+		prefix, complete = head(exp.Subs[0], flags)
+		for _, sub := range exp.Subs[1:] {
+			h, c := head(sub, flags)
+			n := 0
+			for n < len(h) && n < len(prefix) && h[n] == prefix[n] {
+				n++
+			}
+			n = runePrefix(h, prefix, n)
+			complete = complete && c && n == len(h) && n == len(prefix)
+			prefix = prefix[:n]
+		}
+		return prefix, complete
+	case OpIntegerRange, OpCharRange:
+		sta, num, sep, wid := rangeData(exp)
+		if num == 0 {
+			return appendRangeValue(nil, exp.Op, sta, wid, rangeFormat(exp)), true
+		}
+		// every item sorts between the bounds, as long as the bounds of an
+		// integer range have the same sign and width
+		end := sta + num*sep
+		a := appendRangeValue(nil, exp.Op, sta, wid, rangeFormat(exp))
+		b := appendRangeValue(nil, exp.Op, end, wid, rangeFormat(exp))
+		if exp.Op == OpIntegerRange && ((sta < 0) != (end < 0) || len(a) != len(b)) {
+			return nil, false
+		}
+		n := 0
+		for n < len(a) && n < len(b) && a[n] == b[n] {
+			n++
+		}
+		return a[:runePrefix(a, b, n)], false
+	default:
+		return appendConst(nil, exp, flags), true
+	}
+}
+
+// disjoint reports whether the items of an alternate can be proven to
+// never produce the same string.
+func disjoint(subs []*BraceExp, flags ExpandFlags) bool {
+	// items of distinct fixed lengths
+	lens := make(map[int]bool, len(subs))
+	for _, sub := range subs {
+		min, max := lenBounds(sub, flags)
+		if min != max || lens[min] {
+			lens = nil
+			break
+		}
+		lens[min] = true
+	}
+	if lens != nil {
+		return true
+	}
+
+	// items starting with distinct literal text
+	type item struct {
+		head     []byte
+		complete bool
+	}
+	items := make([]item, len(subs))
+	for i, sub := range subs {
+		items[i].head, items[i].complete = head(sub, flags)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return string(items[i].head) < string(items[j].head)
+	})
+	for i := 1; i < len(items); i++ {
+		a, b := &items[i-1], &items[i]
+		if len(a.head) > len(b.head) || string(a.head) != string(b.head[:len(a.head)]) {
+			continue
+		}
+		if a.complete && len(a.head) < len(b.head) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// isUnique reports whether exp can be proven to produce no duplicates.
+func isUnique(exp *BraceExp, flags ExpandFlags) bool {
+	switch exp.Op {
+	case OpConcat:
+		subs := exp.Subs
+		for _, sub := range subs {
+			if !isUnique(sub, flags) {
+				return false
+			}
+		}
+		// a split point is unambiguous if every part before it, or every
+		// part after it, has a fixed length
+		front, back := true, true
+		for i, sub := range subs {
+			if i < len(subs)-1 && front {
+				front = fixedLen(sub, flags)
+			}
+			if i > 0 && back {
+				back = fixedLen(sub, flags)
+			}
+		}
+		return front || back
+	case OpAlternate:
+		for _, sub := range exp.Subs {
+			if !isUnique(sub, flags) {
+				return false
+			}
+		}
+		return disjoint(exp.Subs, flags)
+	default:
+		return true
+	}
+}
+
+func uniqueHandler(handler WalkBytesHandler) WalkBytesHandler {
+	seen := make(map[string]struct{})
+	return func(b []byte) {
+		if _, ok := seen[string(b)]; ok {
+			return
+		}
+		seen[string(b)] = struct{}{}
+		handler(b)
+	}
+}
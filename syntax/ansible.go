@@ -0,0 +1,113 @@
+package syntax
+
+import (
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrHostRange       = "invalid host range"
+	ErrHostRangeBounds = "host range must be begin:end or begin:end:step"
+	ErrHostRangeEnd    = "host range must specify end value"
+	ErrHostRangeWidth  = "host range must specify equal-length begin and end formats"
+	ErrHostRangeOrder  = "host range must have begin <= end"
+)
+
+// asciiLetters orders letters like Python's string.ascii_letters, which
+// Ansible indexes to make letter ranges.
+const asciiLetters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// letterRange returns the letters from index sta to end of asciiLetters by
+// step, as one char range per case.
+func letterRange(sta, end, step int) *BraceExp {
+	var items []*BraceExp
+	if sta < 26 {
+		last := sta + (min(end, 25)-sta)/step*step
+		items = append(items, newRange(OpCharRange, 'a'+sta, (last-sta)/step, step, 0, 0))
+		sta = last + step
+	}
+	if sta <= end {
+		items = append(items, newRange(OpCharRange, 'A'+sta-26, (end-sta)/step, step, 0, 0))
+	}
+	return newAlternate(items...)
+}
+
+// ansibleRange parses the text of a host range: begin:end or begin:end:step,
+// where an empty begin is zero. pos is the offset of the range for errors.
+func ansibleRange(text string, pos int) (*BraceExp, error) {
+	bounds := strings.Split(text, ":")
+	if len(bounds) != 2 && len(bounds) != 3 {
+		return nil, &Error{ErrHostRangeBounds, pos}
+	}
+	beg, end, step := bounds[0], bounds[1], 1
+	if len(bounds) == 3 {
+		var err error
+		if step, err = strconv.Atoi(bounds[2]); err != nil || step <= 0 {
+			return nil, &Error{ErrHostRange, pos}
+		}
+	}
+	if beg == "" {
+		beg = "0"
+	}
+	if end == "" {
+		return nil, &Error{ErrHostRangeEnd, pos}
+	}
+	wid := 0
+	if beg[0] == '0' && len(beg) > 1 {
+		if wid = len(beg); wid != len(end) {
+			return nil, &Error{ErrHostRangeWidth, pos}
+		}
+	}
+
+	// like str.index, a bound may be any run of letters
+	if i, j := strings.Index(asciiLetters, beg), strings.Index(asciiLetters, end); i >= 0 && j >= 0 {
+		if i > j {
+			return nil, &Error{ErrHostRangeOrder, pos}
+		}
+		return letterRange(i, j, step), nil
+	}
+	sta, err1 := strconv.Atoi(beg)
+	last, err2 := strconv.Atoi(end)
+	if err1 != nil || err2 != nil {
+		return nil, &Error{ErrHostRange, pos}
+	}
+	if sta > last {
+		// Ansible makes no host at all, which a tree cannot hold
+		return nil, &Error{ErrHostRangeOrder, pos}
+	}
+	return newRange(OpIntegerRange, sta, (last-sta)/step, step, wid, 0), nil
+}
+
+// parseAnsible parses a host pattern like expand_hostname_range of Ansible:
+// the text up to the first '[' and the first ']' is the head, the text
+// between them a range, and the rest is parsed again. Ranges are inclusive
+// and a zero-padded begin pads every number to its width.
+func parseAnsible(input string) (*BraceExp, error) {
+	var subs []*BraceExp
+	pos := 0
+	for {
+		rest := input[pos:]
+		i := strings.IndexByte(rest, '[')
+		if i < 0 {
+			break
+		}
+		j := strings.IndexByte(rest, ']')
+		if j < 0 || strings.IndexByte(rest, '|') >= 0 {
+			return nil, &Error{ErrHostRange, pos + i}
+		}
+		i, j = min(i, j), max(i, j)
+		exp, err := ansibleRange(rest[i+1:j], pos+i)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			subs = append(subs, newLiteral([]byte(rest[:i])))
+		}
+		subs = append(subs, exp)
+		pos += j + 1
+	}
+	if pos < len(input) {
+		subs = append(subs, newLiteral([]byte(input[pos:])))
+	}
+	return newConcat(subs...), nil
+}
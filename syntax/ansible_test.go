@@ -0,0 +1,36 @@
+package syntax_test
+
+import (
+	"testing"
+
+	"github.com/pierre-primary/go-braces/syntax"
+)
+
+func TestAnsibleDialect(t *testing.T) {
+	equal := DefineParseExpand(t, syntax.AnsibleDialect)
+	fail := DefineParseError(t, syntax.AnsibleDialect)
+
+	equal("www[01:09:3].example.com", E{"www01.example.com", "www04.example.com", "www07.example.com"})
+	equal("db-[a:c]-node", E{"db-a-node", "db-b-node", "db-c-node"})
+	equal("h[:2]", E{"h0", "h1", "h2"})
+	equal("h[8:10]", E{"h8", "h9", "h10"})
+	equal("h[098:100]", E{"h098", "h099", "h100"})
+	equal("h[-1:1]", E{"h-1", "h0", "h1"})
+	equal("[x:C:2]", E{"x", "z", "B"})
+	equal("[Y:Z]", E{"Y", "Z"})
+	equal("[ab:cd]", E{"a", "b", "c"})
+	equal("r[1:2]n[a:b]", E{"r1na", "r1nb", "r2na", "r2nb"})
+	equal("plain]host", E{"plain]host"})
+
+	fail("h[1:2:3:4]", syntax.ErrHostRangeBounds)
+	fail("h[5]", syntax.ErrHostRangeBounds)
+	fail("h[1:]", syntax.ErrHostRangeEnd)
+	fail("h[01:100]", syntax.ErrHostRangeWidth)
+	fail("h[c:a]", syntax.ErrHostRangeOrder)
+	fail("h[A:z]", syntax.ErrHostRangeOrder)
+	fail("h[5:1]", syntax.ErrHostRangeOrder)
+	fail("h[1:5:0]", syntax.ErrHostRange)
+	fail("h[a:5]", syntax.ErrHostRange)
+	fail("h[1:2", syntax.ErrHostRange)
+	fail("h[1:2]|x", syntax.ErrHostRange)
+}
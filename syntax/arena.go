@@ -0,0 +1,35 @@
+package syntax
+
+import (
+	"slices"
+	"unsafe"
+)
+
+// ExpandArena is like Expand, but all strings share one backing array. The
+// number of strings and their summed length are computed up front, so the
+// cost of the expansion no longer grows with the number of strings: one
+// arena, which also holds the walk buffer, and one grown slice. Keeping any
+// of the strings alive keeps the whole arena alive.
+func (n *BraceExp) ExpandArena(data []string, flags ...ExpandFlags) []string {
+	var flag ExpandFlags
+	for _, f := range flags {
+		flag |= f
+	}
+	x := newIndexer()
+	count := x.count(n)
+	ok, total := x.total(n, flag)
+	if count < 0 || !ok {
+		return n.Expand(data, flag)
+	}
+
+	_, longest := lenBounds(n, flag)
+	mem := make([]byte, total+longest)
+	arena, buffer := mem[:0:total], mem[total:total]
+	data = slices.Grow(data, count)
+	walkExp(n, flag, func(b []byte) {
+		off := len(arena)
+		arena = append(arena, b...)
+		data = append(data, unsafe.String(unsafe.SliceData(arena[off:]), len(b)))
+	}, buffer, nil)
+	return data
+}
@@ -0,0 +1,65 @@
+package syntax
+
+import "bytes"
+
+// SplitBase splits exp into the longest literal directory prefix of its
+// expansion, trailing slash included, and an expression producing the rest
+// of each string, like fast-glob's getBase. Only the constant parts at the
+// start of exp make up the base; an escaped slash is a separator, and the
+// split never happens inside quotes. When exp has no static
+// directory, base is empty and rest is a copy of exp.
+func SplitBase(exp *BraceExp, flags ...ExpandFlags) (base string, rest *BraceExp) {
+	var flag ExpandFlags
+	for _, f := range flags {
+		flag |= f
+	}
+	subs := []*BraceExp{exp}
+	if exp.Op == OpConcat {
+		subs = exp.Subs
+	}
+
+	// find the part holding the last slash, and the offset after it
+	idx, off := -1, 0
+	var que byte
+	for i, sub := range subs {
+		if !isConst(sub) {
+			break
+		}
+		switch sub.Op {
+		case OpQuote:
+			if que == 0 {
+				que = sub.Val[0]
+			} else if que == sub.Val[0] {
+				que = 0
+			}
+		case OpEscape:
+			if que == 0 && len(sub.Val) > 1 && sub.Val[1] == '/' {
+				idx, off = i, len(sub.Val)
+			}
+		case OpLiteral:
+			if k := bytes.LastIndexByte(sub.Val, '/'); que == 0 && k >= 0 {
+				idx, off = i, k+1
+			}
+		}
+	}
+	if idx < 0 {
+		return "", clone(exp)
+	}
+
+	var buf []byte
+	for _, sub := range subs[:idx] {
+		buf = appendConst(buf, sub, flag)
+	}
+	split := subs[idx]
+	parts := make([]*BraceExp, 0, len(subs)-idx)
+	if off < len(split.Val) {
+		buf = append(buf, split.Val[:off]...)
+		parts = append(parts, newLiteral(split.Val[off:]))
+	} else {
+		buf = appendConst(buf, split, flag)
+	}
+	for _, sub := range subs[idx+1:] {
+		parts = append(parts, clone(sub))
+	}
+	return string(buf), newConcat(parts...)
+}
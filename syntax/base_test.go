@@ -0,0 +1,46 @@
+package syntax_test
+
+import (
+	"testing"
+
+	"github.com/pierre-primary/go-braces/syntax"
+)
+
+func TestSplitBase(t *testing.T) {
+	split := func(input string, flags syntax.ExpandFlags, base, rest string) {
+		exp, err := syntax.Parse(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, r := syntax.SplitBase(exp, flags)
+		if b != base || r.String() != rest {
+			t.Fatal(input, b, r.String())
+		}
+		// base followed by rest gives back the expansion
+		var want []string
+		exp.Walk(func(str string) { want = append(want, str) }, flags)
+		got := r.Expand(nil, flags)
+		if len(got) != len(want) {
+			t.Fatal(input, got, want)
+		}
+		for i := range got {
+			if b+got[i] != want[i] {
+				t.Fatal(input, b, got[i], want[i])
+			}
+		}
+	}
+
+	split("assets/img/{a,b}.png", 0, "assets/img/", "{a,b}.png")
+	split("assets/img/{a,b}/x.png", 0, "assets/img/", "{a,b}/x.png")
+	split("a/b/c", 0, "a/b/", "c")
+	split("{a,b}/c", 0, "", "{a,b}/c")
+	split("abc", 0, "", "abc")
+	split("a/", 0, "a/", "")
+	split(`a\/b{x,y}`, 0, "a/", "b{x,y}")
+	split(`a\/b{x,y}`, KeepEscape, `a\/`, "b{x,y}")
+	split(`"x/y"/z{1..3}`, 0, "x/y/", "z{1..3}")
+	split(`"x/y"z{1..3}`, 0, "", `"x/y"z{1..3}`)
+	split(`"x/y"z{1..3}`, KeepQuote, "", `"x/y"z{1..3}`)
+	split(`"x/y"/z{1..3}`, KeepQuote, `"x/y"/`, "z{1..3}")
+	split(`a/b\`, 0, "a/", `b\`)
+}
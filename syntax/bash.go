@@ -0,0 +1,380 @@
+package syntax
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// bashParser parses like brace_expand in GNU bash 5 (braces.c): it works on
+// the raw text, finds the first brace that has a matching close brace, then
+// parses the preamble, the amble and the postamble on their own. Quotes and
+// escapes are kept in the tree, as bash removes them after the expansion.
+//
+// Bash also reparses the characters a letter sequence produces: a generated
+// backslash escapes the character after it, and a generated backquote
+// starts a command substitution. Only the removal of the backslash is
+// followed here.
+type bashParser struct {
+	input string
+	flags ParseFlags
+}
+
+func isBashSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
+}
+
+func isAlpha(c byte) bool {
+	return isLowerCase(c) || isUpperCase(c)
+}
+
+// gobble returns the index of the first satisfy in input[i:end] that is not
+// quoted, escaped or nested in braces, or end if there is none. sta is the
+// start of the text being expanded, like brace_gobbler.
+func (p *bashParser) gobble(sta, i, end int, satisfy byte) int {
+	input := p.input
+	level, commas := 0, 1
+	if satisfy == '}' {
+		// a close brace only counts after a comma or a sequence operator
+		commas = 0
+	}
+	var quoted byte
+	for ; i < end; i++ {
+		c := input[i]
+		if c == '\\' && p.flags&IgnoreEscape == 0 && quoted != '\'' {
+			i++
+			continue
+		}
+		if c == '$' && i+1 < end && input[i+1] == '{' && quoted != '\'' {
+			// ${...} is parameter expansion
+			i++
+			if quoted == 0 {
+				level++
+			}
+			continue
+		}
+		if quoted != 0 {
+			if c == quoted {
+				quoted = 0
+			}
+			continue
+		}
+		if (c == '"' || c == '\'' || c == '`') && p.flags&IgnoreQuote == 0 {
+			quoted = c
+			continue
+		}
+		if c == satisfy && level == 0 && commas > 0 {
+			// an open brace between whitespace, or before a close brace
+			// after whitespace, is not one
+			if c == '{' && (i == sta || isBashSpace(input[i-1])) &&
+				i+1 < end && (isBashSpace(input[i+1]) || input[i+1] == '}') {
+				continue
+			}
+			return i
+		}
+		switch {
+		case c == '{':
+			level++
+		case c == '}' && level > 0:
+			level--
+		case satisfy == '}' && level == 0 && c == ',':
+			commas++
+		case satisfy == '}' && level == 0 && c == '.' && i+1 < end && input[i+1] == '.' &&
+			(i+2 >= end || input[i+2] != '}'):
+			commas++
+		}
+	}
+	return end
+}
+
+func (p *bashParser) expand(sta, end int) (*BraceExp, error) {
+	// find the first open brace with a matching close brace
+	i, j := sta, end
+	for {
+		if i = p.gobble(sta, i, end, '{'); i >= end {
+			return p.text(sta, end)
+		}
+		if j = p.gobble(sta, i+1, end, '}'); j < end {
+			break
+		}
+		i++
+	}
+
+	var mid *BraceExp
+	if p.hasComma(i+1, j) {
+		var err error
+		if mid, err = p.amble(i+1, j); err != nil {
+			return nil, err
+		}
+	} else if mid = p.seq(p.input[i+1 : j]); mid == nil {
+		// neither a list nor a sequence, the braces stay
+		lit, err := p.text(sta, j+1)
+		if err != nil {
+			return nil, err
+		}
+		post, err := p.expand(j+1, end)
+		if err != nil {
+			return nil, err
+		}
+		return bashConcat(lit, post), nil
+	}
+
+	pre, err := p.text(sta, i)
+	if err != nil {
+		return nil, err
+	}
+	post, err := p.expand(j+1, end)
+	if err != nil {
+		return nil, err
+	}
+	return bashConcat(pre, mid, post), nil
+}
+
+// hasComma reports whether input[sta:end] holds a comma that is not
+// escaped. Unlike gobble it does not look at quotes or nested braces, so a
+// quoted comma still makes a list of a single item.
+func (p *bashParser) hasComma(sta, end int) bool {
+	for i := sta; i < end; i++ {
+		switch p.input[i] {
+		case '\\':
+			if p.flags&IgnoreEscape == 0 {
+				i++
+			}
+		case ',':
+			return true
+		}
+	}
+	return false
+}
+
+func (p *bashParser) amble(sta, end int) (*BraceExp, error) {
+	var items []*BraceExp
+	for {
+		i := p.gobble(sta, sta, end, ',')
+		item, err := p.expand(sta, i)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if i >= end {
+			return newAlternate(items...), nil
+		}
+		sta = i + 1
+	}
+}
+
+// bashNumber parses like legal_number: blanks around the number are allowed.
+func bashNumber(s string) (ok bool, n int) {
+	s = strings.TrimLeft(s, " \t\n\v\f\r")
+	i := 0
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		i++
+	}
+	j := i
+	for j < len(s) && isDigit(s[j]) {
+		j++
+	}
+	if j == i || strings.TrimLeft(s[j:], " \t\n\v\f\r") != "" {
+		return false, 0
+	}
+	return parseInt([]byte(s[:j]))
+}
+
+// seq parses the amble of a sequence expression like expand_seqterm and
+// sizes it like mkseq, or returns nil.
+func (p *bashParser) seq(text string) *BraceExp {
+	k := strings.Index(text, "..")
+	if k <= 0 || k+2 >= len(text) {
+		return nil
+	}
+	lhs, rhs := text[:k], text[k+2:]
+
+	op := OpUnknown
+	var sta, end int
+	if ok, n := bashNumber(lhs); ok {
+		op, sta = OpIntegerRange, n
+	} else if len(lhs) == 1 && isAlpha(lhs[0]) {
+		op, sta = OpCharRange, int(lhs[0])
+	} else {
+		return nil
+	}
+
+	var num, rest string
+	switch {
+	case isDigit(rhs[0]) || ((rhs[0] == '+' || rhs[0] == '-') && len(rhs) > 1 && isDigit(rhs[1])):
+		i := 1
+		for i < len(rhs) && isDigit(rhs[i]) {
+			i++
+		}
+		var ok bool
+		if ok, end = parseInt([]byte(rhs[:i])); !ok || op != OpIntegerRange {
+			return nil
+		}
+		num, rest = rhs[:i], rhs[i:]
+	case isAlpha(rhs[0]) && (len(rhs) == 1 || rhs[1] == '.'):
+		if op != OpCharRange {
+			return nil
+		}
+		end, rest = int(rhs[0]), rhs[1:]
+	default:
+		return nil
+	}
+
+	incr := 1
+	if len(rest) > 2 && rest[:2] == ".." {
+		s := strings.TrimLeft(rest[2:], " \t\n\v\f\r")
+		i := 0
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		for i < len(s) && isDigit(s[i]) {
+			i++
+		}
+		var ok bool
+		if ok, incr = parseInt([]byte(s[:i])); !ok || i != len(s) || i == 0 {
+			return nil
+		}
+	} else if rest != "" {
+		return nil
+	}
+
+	// a zero-padded bound pads every item to the longest bound
+	wid := 0
+	if op == OpIntegerRange {
+		padded := func(s string) bool {
+			return (len(s) > 1 && s[0] == '0') || (len(s) > 2 && s[0] == '-' && s[1] == '0')
+		}
+		if padded(lhs) || padded(num) {
+			wid = max(len(lhs), len(num))
+		}
+	}
+
+	if incr == 0 {
+		incr = 1
+	}
+	if sta > end && incr > 0 {
+		incr = -incr
+	} else if sta < end && incr < 0 {
+		if incr == -MaxInt-1 {
+			return nil
+		}
+		incr = -incr
+	}
+	if (sta > 0 && end < -MaxInt-1+3+sta) || (sta < 0 && end > MaxInt-2+sta) {
+		return nil
+	}
+	n := 0
+	if incr != -MaxInt-1 {
+		n = int(absToUint(end-sta) / absToUint(incr))
+	}
+	if n > 1<<31-1-3 {
+		return nil
+	}
+
+	// bash removes a backslash produced by a letter sequence with the
+	// quotes, so that item is empty
+	if d := '\\' - sta; op == OpCharRange && d%incr == 0 && d/incr >= 0 && d/incr <= n {
+		k := d / incr
+		items := []*BraceExp{{Op: OpEmpty}}
+		if k > 0 {
+			items = append([]*BraceExp{newRange(op, sta, k-1, incr, wid, 0)}, items...)
+		}
+		if k < n {
+			items = append(items, newRange(op, sta+(k+1)*incr, n-k-1, incr, wid, 0))
+		}
+		return newAlternate(items...)
+	}
+	return newRange(op, sta, n, incr, wid, 0)
+}
+
+// text turns input[sta:end], which holds no brace expansion, into literal,
+// escape and quote nodes. Inside double quotes a backslash only escapes the
+// characters it escapes in bash.
+func (p *bashParser) text(sta, end int) (*BraceExp, error) {
+	input := p.input
+	var subs []*BraceExp
+	lit := sta
+	emit := func(op Op, i, j int) {
+		if lit < i {
+			subs = append(subs, newLiteral([]byte(input[lit:i])))
+		}
+		exp := &BraceExp{Op: op}
+		exp.Val = append(exp.Val0[:0], input[i:j]...)
+		subs = append(subs, exp)
+		lit = j
+	}
+
+	var que byte
+	queSta := 0
+	for i := sta; i < end; i++ {
+		c := input[i]
+		switch {
+		case c == '\\' && p.flags&IgnoreEscape == 0 && que != '\'':
+			if i+1 >= end {
+				if p.flags&StrictMode != 0 {
+					return nil, &Error{ErrTrailingBackslash, -1}
+				}
+				emit(OpEscape, i, i+1)
+				continue
+			}
+			if que == '"' && strings.IndexByte("$`\"\\\n", input[i+1]) < 0 {
+				i++
+				continue
+			}
+			_, w := utf8.DecodeRuneInString(input[i+1 : end])
+			emit(OpEscape, i, i+1+w)
+			i += w
+		case que != 0:
+			if c == que {
+				emit(OpQuote, i, i+1)
+				que = 0
+			}
+		case (c == '"' || c == '\'') && p.flags&IgnoreQuote == 0:
+			emit(OpQuote, i, i+1)
+			que, queSta = c, i
+		}
+	}
+	if que != 0 && p.flags&StrictMode != 0 {
+		return nil, &Error{ErrMissingQuote, queSta}
+	}
+	if lit < end {
+		subs = append(subs, newLiteral([]byte(input[lit:end])))
+	}
+	return bashConcat(subs...), nil
+}
+
+// bashConcat joins subs like newConcat, dropping empty parts and merging
+// adjacent literals.
+func bashConcat(subs ...*BraceExp) *BraceExp {
+	var parts []*BraceExp
+	for _, sub := range subs {
+		items := []*BraceExp{sub}
+		if sub.Op == OpConcat {
+			items = sub.Subs
+		}
+		for _, item := range items {
+			if item.Op == OpEmpty {
+				continue
+			}
+			if n := len(parts); n > 0 && item.Op == OpLiteral && parts[n-1].Op == OpLiteral {
+				parts[n-1].Val = append(parts[n-1].Val, item.Val...)
+				continue
+			}
+			parts = append(parts, item)
+		}
+	}
+	return newConcat(parts...)
+}
+
+func parseBash(input string, flags ParseFlags) (*BraceExp, error) {
+	if flags&StrictMode != 0 {
+		for i := 0; i < len(input); {
+			c, w := utf8.DecodeRuneInString(input[i:])
+			if c == utf8.RuneError && w <= 1 {
+				return nil, &Error{ErrInvalidUTF8, i}
+			}
+			i += w
+		}
+	}
+	p := bashParser{input: input, flags: flags}
+	return p.expand(0, len(input))
+}
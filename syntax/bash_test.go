@@ -0,0 +1,101 @@
+package syntax_test
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/pierre-primary/go-braces/syntax"
+)
+
+func TestBashCompat(t *testing.T) {
+	equal := DefineParseExpand(t, syntax.BashCompat)
+
+	equal(`a/{b,c}/d`, E{"a/b/d", "a/c/d"})
+	equal(`{1..2..-9223372036854775808}`, E{"{1..2..-9223372036854775808}"})
+	equal(`{-01..2}`, E{"-01", "000", "001", "002"})
+	equal(`{-1..02}`, E{"-1", "00", "01", "02"})
+	equal(`{-0..2}`, E{"0", "1", "2"})
+	equal(`{01..+3}`, E{"01", "02", "03"})
+	equal(`{Z..a..2}`, E{"Z", "", "^", "`"})
+	equal(`x{b..Y..3}{a,b}`, E{"xba", "xbb", "x_a", "x_b", "xa", "xb", "xYa", "xYb"})
+	equal(`{a..e..-2}`, E{"a", "c", "e"})
+	equal(`{1..5..0}`, E{"1", "2", "3", "4", "5"})
+	equal(`{\a..c}`, E{"{a..c}"})
+	equal(`{"a"..c}`, E{"{a..c}"})
+	equal(`{a,"b,c"}`, E{"a", "b,c"})
+	equal(`{"a,b"}`, E{"{a,b}"})
+	equal(`{a..b..c{1,2}}`, E{"a..b..c1", "a..b..c2"})
+	equal(`"a\"b\c"'d\'`, E{`a"b\cd\`})
+	equal(`{},a}`, E{"{},a}"})
+	equal(`x{},a}`, E{"x}", "xa"})
+	equal(`{a}b,c}`, E{"a}b", "c"})
+	equal(`{a..}b,c}`, E{"a..}b", "c"})
+	equal(`{a..c}b,c}`, E{"ab,c}", "bb,c}", "cb,c}"})
+	equal(`{a{b,c}`, E{"{ab", "{ac"})
+	equal(`{a..b..c}{1,2}`, E{"{a..b..c}1", "{a..b..c}2"})
+	equal(`${a,b}{c,d}`, E{"${a,b}c", "${a,b}d"})
+	equal(`{ }`, E{"{ }"})
+}
+
+// bashPattern generates a random word from pieces that exercise braces,
+// sequences, escapes and quotes, keeping quotes balanced.
+func bashPattern(rng *rand.Rand) string {
+	pieces := []string{
+		"a", "b", "Z", "x", "0", "1", "2", "9", "05", "-1", "-02", "+1",
+		"{a..Z..3}", "{b..e}", "{-02..1}", "{1..-9..4}", "{a,b}", "{,1}",
+		"{", "{", "{", "}", "}", "}", ",", ",", "..", "..", ".",
+		`\{`, `\}`, `\,`, `\.`, `\a`, `\\`,
+		`'a,b'`, `'{'`, `'\'`, `"a\"b"`, `"\\"`, `"\a"`, `"{x,y}"`, `"..,"`,
+	}
+	var b strings.Builder
+	for n := 1 + rng.IntN(10); n > 0; n-- {
+		b.WriteString(pieces[rng.IntN(len(pieces))])
+	}
+	return b.String()
+}
+
+func TestBashDifferential(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not found")
+	}
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	patterns := make([]string, 2000)
+	var script strings.Builder
+	script.WriteString("set -f\n")
+	for i := range patterns {
+		// the prefix keeps every word non-empty and away from tilde expansion
+		patterns[i] = "x" + bashPattern(rng)
+		script.WriteString("printf '%s\\n' " + patterns[i] + "\nprintf '@@\\n'\n")
+	}
+
+	cmd := exec.Command(bash, "--norc", "--noprofile")
+	cmd.Stdin = strings.NewReader(script.String())
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := bytes.Split(out, []byte("@@\n"))
+	if len(results) != len(patterns)+1 {
+		t.Fatal("unexpected bash output", len(results))
+	}
+
+	for i, input := range patterns {
+		exp, err := syntax.Parse(input, syntax.BashCompat)
+		if err != nil {
+			t.Fatal(input, err)
+		}
+		var result bytes.Buffer
+		exp.Walk(func(str string) {
+			result.WriteString(str)
+			result.WriteByte('\n')
+		})
+		if result.String() != string(results[i]) {
+			t.Errorf("%s: bash %q, got %q", input, results[i], result.String())
+		}
+	}
+}
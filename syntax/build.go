@@ -0,0 +1,177 @@
+package syntax
+
+import (
+	"unsafe"
+)
+
+func rangeData(exp *BraceExp) (sta, num, sep, wid int) {
+	rg := unsafe.Slice((*int)(unsafe.Pointer(&exp.Val[0])), 5)
+	return rg[0], rg[1], rg[2], rg[3]
+}
+
+// rangeFormat returns the format of the numbers of an integer range.
+func rangeFormat(exp *BraceExp) numFormat {
+	rg := unsafe.Slice((*int)(unsafe.Pointer(&exp.Val[0])), 5)
+	return numFormat(rg[4])
+}
+
+func packRangeData(sta, num, sep, wid int, f numFormat) []byte {
+	if num == 0 {
+		sep = 0
+	}
+	opts := [5]int{sta, num, sep, wid, int(f)}
+	return unsafe.Slice((*byte)(unsafe.Pointer(&opts[0])), int(unsafe.Sizeof(opts)))
+}
+
+func newLiteral(val []byte) *BraceExp {
+	exp := &BraceExp{Op: OpLiteral}
+	exp.Val = append(exp.Val0[:0], val...)
+	return exp
+}
+
+func newRange(op Op, sta, num, sep, wid int, f numFormat) *BraceExp {
+	return &BraceExp{Op: op, Val: packRangeData(sta, num, sep, wid, f)}
+}
+
+// newConcat joins subs like the parser does: nested concats are flattened
+// and every sub is linked to its successor.
+func newConcat(subs ...*BraceExp) *BraceExp {
+	exp := &BraceExp{Op: OpConcat}
+	for _, sub := range subs {
+		if sub.Op == OpConcat {
+			exp.Subs = append(exp.Subs, sub.Subs...)
+		} else {
+			exp.Subs = append(exp.Subs, sub)
+		}
+	}
+	switch len(exp.Subs) {
+	case 0:
+		return &BraceExp{Op: OpEmpty}
+	case 1:
+		return exp.Subs[0]
+	}
+	last := exp.Subs[0]
+	for _, sub := range exp.Subs[1:] {
+		last.link(sub)
+		last = sub
+	}
+	return exp
+}
+
+func newAlternate(subs ...*BraceExp) *BraceExp {
+	exp := &BraceExp{Op: OpAlternate}
+	for _, sub := range subs {
+		if sub.Op == OpAlternate {
+			exp.Subs = append(exp.Subs, sub.Subs...)
+		} else {
+			exp.Subs = append(exp.Subs, sub)
+		}
+	}
+	if len(exp.Subs) == 1 {
+		return exp.Subs[0]
+	}
+	return exp
+}
+
+// clone returns a deep copy of the sub-tree rooted at exp, without links.
+// The copy is linked again when it is passed to newConcat.
+func clone(exp *BraceExp) *BraceExp {
+	switch exp.Op {
+	case OpConcat:
+		subs := make([]*BraceExp, len(exp.Subs))
+		for i, sub := range exp.Subs {
+			subs[i] = clone(sub)
+		}
+		return newConcat(subs...)
+	case OpAlternate:
+		subs := make([]*BraceExp, len(exp.Subs))
+		for i, sub := range exp.Subs {
+			subs[i] = clone(sub)
+		}
+		return &BraceExp{Op: OpAlternate, Subs: subs}
+	case OpIntegerRange, OpCharRange:
+		sta, num, sep, wid := rangeData(exp)
+		return newRange(exp.Op, sta, num, sep, wid, rangeFormat(exp))
+	default:
+		c := &BraceExp{Op: exp.Op}
+		c.Val = append(c.Val0[:0], exp.Val...)
+		return c
+	}
+}
+
+func (n *BraceExp) Clone() *BraceExp {
+	if n == nil {
+		return nil
+	}
+	return clone(n)
+}
+
+// appendConst appends the output of a leaf node that always expands to a
+// single string.
+func appendConst(buf []byte, exp *BraceExp, flags ExpandFlags) []byte {
+	switch exp.Op {
+	case OpEscape:
+		if flags&KeepEscape == 0 {
+			return append(buf, exp.Val[1:]...)
+		}
+	case OpQuote:
+		if flags&KeepQuote == 0 {
+			return buf
+		}
+	case OpEmpty:
+		return buf
+	}
+	return append(buf, exp.Val...)
+}
+
+func isConst(exp *BraceExp) bool {
+	switch exp.Op {
+	case OpLiteral, OpEscape, OpQuote, OpEmpty:
+		return true
+	}
+	return false
+}
+
+// Range returns the start, the number of steps, the step and the padded
+// width of an integer or a character range.
+func (n *BraceExp) Range() (sta, num, sep, wid int) {
+	return rangeData(n)
+}
+
+// IntFormat returns how the numbers of an integer range are written: the
+// base, 10, 16 or 8, whether hex digits are upper-case and the prefix
+// before the digits.
+func (n *BraceExp) IntFormat() (base int, upper bool, prefix string) {
+	f := rangeFormat(n)
+	return int(f.base()), f&fmtUpper != 0, f.prefix()
+}
+
+// NewLiteral returns a node that expands to s.
+func NewLiteral(s string) *BraceExp {
+	return newLiteral([]byte(s))
+}
+
+// NewIntegerRange returns a node counting from sta towards end by the
+// absolute value of step, padding every number to wid digits. A zero step
+// counts by one.
+func NewIntegerRange(sta, end, step, wid int) *BraceExp {
+	if step == 0 {
+		step = 1
+	}
+	n := absToUint(end-sta) / absToUint(step)
+	sep := int(absToUint(step))
+	if sta > end {
+		sep = -sep
+	}
+	return newRange(OpIntegerRange, sta, int(n), sep, wid, 0)
+}
+
+// NewConcat returns the concatenation of subs.
+func NewConcat(subs ...*BraceExp) *BraceExp {
+	return newConcat(subs...)
+}
+
+// NewAlternate returns a node that expands to each of subs in turn.
+func NewAlternate(subs ...*BraceExp) *BraceExp {
+	return newAlternate(subs...)
+}
@@ -0,0 +1,208 @@
+package syntax
+
+import (
+	"unicode/utf8"
+)
+
+func commonPrefix(strs []string) int {
+	n := len(strs[0])
+	for _, str := range strs[1:] {
+		if len(str) < n {
+			n = len(str)
+		}
+		for i := 0; i < n; i++ {
+			if str[i] != strs[0][i] {
+				n = i
+				break
+			}
+		}
+	}
+	// never split a UTF-8 sequence
+	for n > 0 && n < len(strs[0]) && !utf8.RuneStart(strs[0][n]) {
+		n--
+	}
+	return n
+}
+
+func commonSuffix(strs []string) int {
+	n := len(strs[0])
+	for _, str := range strs[1:] {
+		if len(str) < n {
+			n = len(str)
+		}
+		for i := 1; i <= n; i++ {
+			if str[len(str)-i] != strs[0][len(strs[0])-i] {
+				n = i - 1
+				break
+			}
+		}
+	}
+	for n > 0 && !utf8.RuneStart(strs[0][len(strs[0])-n]) {
+		n--
+	}
+	return n
+}
+
+// intFormat detects whether all strs are integers written with one common
+// zero padding, and returns that padding.
+func intFormat(strs []string) (ok bool, wid int, vals []int) {
+	vals = make([]int, len(strs))
+	for i, str := range strs {
+		if len(str) == 0 || str[0] == '+' {
+			return false, 0, nil
+		}
+		if ok, vals[i] = parseInt([]byte(str)); !ok {
+			return false, 0, nil
+		}
+		if len(str) >= 2 && (str[0] == '0' || (str[0] == '-' && str[1] == '0')) {
+			wid = len(str)
+		}
+	}
+	for i, str := range strs {
+		if string(appendNumber(nil, vals[i], wid)) != str {
+			return false, 0, nil
+		}
+	}
+	return true, wid, vals
+}
+
+// charFormat detects whether all strs are single letters of the same case.
+func charFormat(strs []string) (ok bool, vals []int) {
+	vals = make([]int, len(strs))
+	var upper bool
+	for i, str := range strs {
+		if len(str) != 1 || !(isLowerCase(str[0]) || isUpperCase(str[0])) {
+			return false, nil
+		}
+		if i == 0 {
+			upper = isUpperCase(str[0])
+		} else if upper != isUpperCase(str[0]) {
+			return false, nil
+		}
+		vals[i] = int(str[0])
+	}
+	return true, vals
+}
+
+// padded reports whether num written with wid shows zeros of padding.
+func padded(num, wid int) bool {
+	return intLen(num, wid, 0) > intLen(num, 0, 0)
+}
+
+// keepsWidth reports whether a range over vals keeps the padding of its
+// items once written: a pattern only holds the width of a padded bound.
+func keepsWidth(vals []int, wid int) bool {
+	if wid == 0 || padded(vals[0], wid) || padded(vals[len(vals)-1], wid) {
+		return true
+	}
+	for _, v := range vals {
+		if padded(v, wid) {
+			return false
+		}
+	}
+	return true
+}
+
+// compressRuns folds vals into arithmetic runs. Runs shorter than three
+// items, or whose padding a range cannot hold, are kept as literals.
+func compressRuns(op Op, vals []int, wid int) *BraceExp {
+	var items []*BraceExp
+	for i := 0; i < len(vals); {
+		j := i + 1
+		if j < len(vals) && vals[j] != vals[i] {
+			sep := vals[j] - vals[i]
+			for j < len(vals) && vals[j]-vals[j-1] == sep {
+				j++
+			}
+		}
+		if j-i >= 3 && (op != OpIntegerRange || keepsWidth(vals[i:j], wid)) {
+			items = append(items, newRange(op, vals[i], j-i-1, vals[i+1]-vals[i], wid, 0))
+		} else {
+			j = i + 1
+			items = append(items, newLiteral(appendRangeValue(nil, op, vals[i], wid, 0)))
+		}
+		i = j
+	}
+	return newAlternate(items...)
+}
+
+func compress(strs []string) *BraceExp {
+	if len(strs) == 1 {
+		return newLiteral([]byte(strs[0]))
+	}
+
+	pre := commonPrefix(strs)
+	mids := make([]string, len(strs))
+	for i, str := range strs {
+		mids[i] = str[pre:]
+	}
+	suf := commonSuffix(mids)
+	for i, str := range mids {
+		mids[i] = str[:len(str)-suf]
+	}
+
+	// keep digit runs whole so that they can become integer ranges
+	if leading, trailing := true, true; pre > 0 || suf > 0 {
+		for _, mid := range mids {
+			leading = leading && len(mid) > 0 && isDigit(mid[0])
+			trailing = trailing && len(mid) > 0 && isDigit(mid[len(mid)-1])
+		}
+		first := strs[0]
+		for leading && pre > 0 && isDigit(first[pre-1]) {
+			pre--
+		}
+		for trailing && suf > 0 && isDigit(first[len(first)-suf]) {
+			suf--
+		}
+		for i, str := range strs {
+			mids[i] = str[pre : len(str)-suf]
+		}
+	}
+
+	var mid *BraceExp
+	if ok, wid, vals := intFormat(mids); ok {
+		mid = compressRuns(OpIntegerRange, vals, wid)
+	} else if ok, vals := charFormat(mids); ok {
+		mid = compressRuns(OpCharRange, vals, 0)
+	} else {
+		// group consecutive items by their first byte
+		var items []*BraceExp
+		for i := 0; i < len(mids); {
+			j := i + 1
+			for j < len(mids) && len(mids[i]) > 0 && len(mids[j]) > 0 && mids[j][0] == mids[i][0] {
+				j++
+			}
+			if j-i == len(mids) && pre == 0 && suf == 0 {
+				// no progress can be made, list the items as they are
+				for _, mid := range mids {
+					items = append(items, newLiteral([]byte(mid)))
+				}
+				break
+			}
+			items = append(items, compress(mids[i:j]))
+			i = j
+		}
+		mid = newAlternate(items...)
+	}
+
+	first := strs[0]
+	subs := make([]*BraceExp, 0, 3)
+	if pre > 0 {
+		subs = append(subs, newLiteral([]byte(first[:pre])))
+	}
+	subs = append(subs, mid)
+	if suf > 0 {
+		subs = append(subs, newLiteral([]byte(first[len(first)-suf:])))
+	}
+	return newConcat(subs...)
+}
+
+// Compress builds a brace expression that expands to strs, in order.
+// Shared prefixes and suffixes are factored out and runs of integers or
+// letters with a constant step are folded into ranges.
+func Compress(strs []string) *BraceExp {
+	if len(strs) == 0 {
+		return nil
+	}
+	return compress(strs)
+}
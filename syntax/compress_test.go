@@ -0,0 +1,48 @@
+package syntax_test
+
+import (
+	"testing"
+
+	"github.com/pierre-primary/go-braces/syntax"
+)
+
+func DefineCompress(t *testing.T) func([]string, string) {
+	return func(input []string, expected string) {
+		exp := syntax.Compress(input)
+		if pattern := exp.String(); pattern != expected {
+			t.Fatal(input, pattern)
+		}
+		// the pattern parses back to the same strings
+		again, err := syntax.Parse(exp.String())
+		if err != nil {
+			t.Fatal(input, err)
+		}
+		result := again.Expand(nil)
+		if len(result) != len(input) {
+			t.Fatal(input, result)
+		}
+		for i := range result {
+			if result[i] != input[i] {
+				t.Fatal(input, result)
+			}
+		}
+	}
+}
+
+func TestCompress(t *testing.T) {
+	equal := DefineCompress(t)
+
+	equal(E{"abc"}, "abc")
+	equal(E{"a", "a"}, "a{,}")
+	equal(E{"a", "ab"}, "a{,b}")
+	equal(E{"web01.dc1", "web02.dc1", "web03.dc1", "db01.dc1"}, "{web{01..03},db01}.dc1")
+	equal(E{"8", "9", "10", "11"}, "{8..11}")
+	equal(E{"08", "09", "10", "11"}, "{08..11}")
+	equal(E{"10", "20", "30", "35"}, "{{10..30..10},35}")
+	equal(E{"a1", "a3", "b1"}, "{a{1,3},b1}")
+	equal(E{"host-a", "host-b", "host-c", "host-e"}, "host-{{a..c},e}")
+	equal(E{"x{1}", "x,2"}, `x{\{1\},\,2}`)
+	equal(E{"10a", "11b"}, "{10a,11b}")
+	equal(E{"-20", "00", "20"}, "{-20,00,20}")
+	equal(E{"10-20", "10-10", "1000", "10100", "10200"}, "10{{-20..00..10},100,200}")
+}
@@ -0,0 +1,50 @@
+package syntax
+
+import (
+	"context"
+)
+
+// checkInterval is the number of strings walked between two checks of a
+// context.
+const checkInterval = 1024
+
+// walkAbort unwinds a walk from inside a handler.
+type walkAbort struct {
+	err error
+}
+
+// recoverAbort stops the unwinding started by a walkAbort and stores its
+// error. Any other panic, such as one raised by a handler, goes on.
+func recoverAbort(err *error) {
+	if r := recover(); r != nil {
+		abort, ok := r.(walkAbort)
+		if !ok {
+			panic(r)
+		}
+		*err = abort.err
+	}
+}
+
+// WalkContext is like Walk but checks ctx every checkInterval strings and
+// returns ctx.Err() as soon as ctx is done.
+func (n *BraceExp) WalkContext(ctx context.Context, handler WalkHandler, flags ...ExpandFlags) (err error) {
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+	var flag ExpandFlags
+	for _, f := range flags {
+		flag |= f
+	}
+
+	defer recoverAbort(&err)
+	steps := 0
+	walkExp(n, flag, stringHandler(handler), nil, func() {
+		if steps++; steps%checkInterval != 0 {
+			return
+		}
+		if err := ctx.Err(); err != nil {
+			panic(walkAbort{err})
+		}
+	})
+	return nil
+}
@@ -0,0 +1,64 @@
+package syntax_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pierre-primary/go-braces/syntax"
+)
+
+func TestWalkContext(t *testing.T) {
+	exp, err := syntax.Parse("{1..1000000}{1..1000000}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, flags := range [][]syntax.ExpandFlags{nil, {Unique}, {Leftmost}} {
+		ctx, cancel := context.WithCancel(context.Background())
+		count := 0
+		err = exp.WalkContext(ctx, func(str string) {
+			if count++; count == 10 {
+				cancel()
+			}
+		}, flags...)
+		if err != context.Canceled {
+			t.Fatal(flags, err)
+		}
+	}
+
+	// sorting collects everything before the handler is called
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := exp.WalkContext(ctx, func(string) {}, Sorted); err != context.DeadlineExceeded {
+		t.Fatal(err)
+	}
+
+	exp, _ = syntax.Parse("{a,b}{1..3}")
+	count := 0
+	if err := exp.WalkContext(context.Background(), func(string) { count++ }); err != nil || count != 6 {
+		t.Fatal(err, count)
+	}
+}
+
+func TestWalkContextPanic(t *testing.T) {
+	exp, _ := syntax.Parse("{1..5000}")
+	boom := errors.New("boom")
+	for _, value := range []any{"boom", boom} {
+		func() {
+			defer func() {
+				if r := recover(); r != value {
+					t.Fatal(r)
+				}
+			}()
+			count := 0
+			exp.WalkContext(context.Background(), func(string) {
+				// after the first context checks
+				if count++; count == 2000 {
+					panic(value)
+				}
+			})
+			t.Fatal("no panic")
+		}()
+	}
+}
@@ -0,0 +1,308 @@
+package syntax
+
+import (
+	"net/netip"
+	"strings"
+)
+
+var (
+	ErrUnmatchedBrace        = "unmatched brace"
+	ErrUnmatchedClose        = "unmatched close brace/bracket"
+	ErrNestedBrace           = "nested brace"
+	ErrEmptyBraces           = "empty string within braces"
+	ErrUnexpectedBracket     = "unexpected close bracket"
+	ErrBadRange              = "bad range"
+	ErrBadRangeSpecification = "bad range specification"
+	ErrTooManyGlobs          = "too many globs"
+)
+
+// curlMaxGlobs is the number of literal and glob parts curl accepts.
+const curlMaxGlobs = 100
+
+// curlParser parses URL globs like curl (tool_urlglob.c): {a,b} sets that do
+// not nest, and [1-10], [01-10:2] or [a-z:2] ranges. Every set and range is
+// a group of the tree, even one with a single item.
+type curlParser struct {
+	input string
+	pos   int
+}
+
+// ipv6End returns the end of an IPv6 literal in brackets at pos, which
+// curl leaves alone, or -1.
+func (p *curlParser) ipv6End() int {
+	i := strings.IndexByte(p.input[p.pos:], ']')
+	if i < 0 {
+		return -1
+	}
+	addr, err := netip.ParseAddr(p.input[p.pos+1 : p.pos+i])
+	if err != nil || !addr.Is6() {
+		return -1
+	}
+	return p.pos + i + 1
+}
+
+func (p *curlParser) literal() []*BraceExp {
+	input := p.input
+	var subs []*BraceExp
+	sta := p.pos
+	for p.pos < len(input) {
+		switch c := input[p.pos]; c {
+		case '{', '}', ']':
+			goto Done
+		case '[':
+			end := p.ipv6End()
+			if end < 0 && p.pos+1 < len(input) && input[p.pos+1] == ']' {
+				end = p.pos + 2
+			}
+			if end < 0 {
+				goto Done
+			}
+			p.pos = end
+		case '\\':
+			// only the glob characters can be escaped
+			if p.pos+1 < len(input) && strings.IndexByte("{[}]", input[p.pos+1]) >= 0 {
+				if sta < p.pos {
+					subs = append(subs, newLiteral([]byte(input[sta:p.pos])))
+				}
+				subs = append(subs, &BraceExp{Op: OpEscape, Val: []byte(input[p.pos : p.pos+2])})
+				p.pos += 2
+				sta = p.pos
+				continue
+			}
+			p.pos++
+		default:
+			p.pos++
+		}
+	}
+Done:
+	if sta < p.pos {
+		subs = append(subs, newLiteral([]byte(input[sta:p.pos])))
+	}
+	return subs
+}
+
+func (p *curlParser) set() (*BraceExp, error) {
+	input := p.input
+	open := p.pos
+	p.pos++
+	exp := &BraceExp{Op: OpAlternate}
+	var item []*BraceExp
+	sta := p.pos
+	flush := func() {
+		if sta < p.pos {
+			item = append(item, newLiteral([]byte(input[sta:p.pos])))
+		}
+	}
+	for {
+		if p.pos >= len(input) {
+			return nil, &Error{ErrUnmatchedBrace, open}
+		}
+		switch input[p.pos] {
+		case '{', '[':
+			return nil, &Error{ErrNestedBrace, p.pos}
+		case ']':
+			return nil, &Error{ErrUnexpectedBracket, p.pos}
+		case '}', ',':
+			if input[p.pos] == '}' && p.pos == open+1 {
+				return nil, &Error{ErrEmptyBraces, p.pos}
+			}
+			flush()
+			exp.Subs = append(exp.Subs, newConcat(item...))
+			item = nil
+			p.pos++
+			sta = p.pos
+			if input[p.pos-1] == '}' {
+				return exp, nil
+			}
+		case '\\':
+			if p.pos+1 < len(input) {
+				flush()
+				item = append(item, &BraceExp{Op: OpEscape, Val: []byte(input[p.pos : p.pos+2])})
+				p.pos += 2
+				sta = p.pos
+				continue
+			}
+			p.pos++
+		default:
+			p.pos++
+		}
+	}
+}
+
+// number reads the digits at pos, skipping blanks first if blank is set.
+func (p *curlParser) number(blank bool) (ok bool, n int, digits string) {
+	input := p.input
+	if blank {
+		for p.pos < len(input) && (input[p.pos] == ' ' || input[p.pos] == '\t') {
+			p.pos++
+		}
+	}
+	sta := p.pos
+	for p.pos < len(input) && isDigit(input[p.pos]) {
+		p.pos++
+	}
+	digits = input[sta:p.pos]
+	if digits == "" {
+		return false, 0, digits
+	}
+	ok, n = parseInt([]byte(digits))
+	return ok, n, digits
+}
+
+func (p *curlParser) rangeExp() (*BraceExp, error) {
+	input := p.input
+	p.pos++
+	if p.pos >= len(input) {
+		return nil, &Error{ErrBadRangeSpecification, p.pos}
+	}
+
+	var op Op
+	var sta, end, wid int
+	step := 1
+	switch c := input[p.pos]; {
+	case isAlpha(c):
+		// "%c-%c]" or "%c-%c:%d]"
+		if p.pos+3 >= len(input) || input[p.pos+1] != '-' {
+			return nil, &Error{ErrBadRange, p.pos}
+		}
+		op, sta, end = OpCharRange, int(c), int(input[p.pos+2])
+		p.pos += 3
+		switch input[p.pos] {
+		case ':':
+			p.pos++
+			var ok bool
+			if ok, step, _ = p.number(false); !ok {
+				step = 0
+			}
+		case ']':
+		default:
+			return nil, &Error{ErrBadRange, p.pos}
+		}
+		if end-sta > 'z'-'a' {
+			step = 0
+		}
+	case isDigit(c):
+		op = OpIntegerRange
+		ok, n, digits := p.number(false)
+		if digits[0] == '0' {
+			wid = len(digits)
+		}
+		sta = n
+		if !ok || p.pos >= len(input) || input[p.pos] != '-' {
+			return nil, &Error{ErrBadRange, p.pos}
+		}
+		p.pos++
+		if ok, end, _ = p.number(true); !ok {
+			return nil, &Error{ErrBadRange, p.pos}
+		}
+		if p.pos < len(input) && input[p.pos] == ':' {
+			p.pos++
+			if ok, step, _ = p.number(false); !ok {
+				step = 0
+			}
+		}
+	default:
+		return nil, &Error{ErrBadRangeSpecification, p.pos}
+	}
+
+	if p.pos >= len(input) || input[p.pos] != ']' || step <= 0 ||
+		(sta == end && step != 1) || (sta != end && (sta > end || step > end-sta)) {
+		return nil, &Error{ErrBadRange, p.pos}
+	}
+	p.pos++
+	return newRange(op, sta, (end-sta)/step, step, wid, 0), nil
+}
+
+func parseCurl(input string) (*BraceExp, error) {
+	p := curlParser{input: input}
+	var subs []*BraceExp
+	size := 0
+	for p.pos < len(input) {
+		if lit := p.literal(); len(lit) > 0 {
+			subs = append(subs, lit...)
+			size++
+		}
+		if p.pos >= len(input) {
+			break
+		}
+
+		var exp *BraceExp
+		var err error
+		switch input[p.pos] {
+		case '{':
+			exp, err = p.set()
+		case '[':
+			exp, err = p.rangeExp()
+		default:
+			return nil, &Error{ErrUnmatchedClose, p.pos}
+		}
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, exp)
+		if size++; size >= curlMaxGlobs {
+			return nil, &Error{ErrTooManyGlobs, p.pos}
+		}
+	}
+	return newConcat(subs...), nil
+}
+
+// GroupHandler receives a string of an expansion together with the strings
+// its top-level groups produced, from left to right.
+type GroupHandler func(str string, groups []string)
+
+// WalkGroups walks the expansion of n like Walk, also passing the part of
+// each string produced by every top-level group: the parts of the root that
+// are not constant.
+func (n *BraceExp) WalkGroups(handler GroupHandler, flags ...ExpandFlags) {
+	var flag ExpandFlags
+	for _, f := range flags {
+		flag |= f
+	}
+	o := odometer{root: newState(n)}
+	parts := []*state{o.root}
+	if n.Op == OpConcat {
+		parts = o.root.subs
+	}
+	var groups []string
+	var buf []byte
+	for {
+		buf, groups = buf[:0], groups[:0]
+		for _, s := range parts {
+			offset := len(buf)
+			buf = s.append(buf, flag)
+			if !isConst(s.exp) {
+				groups = append(groups, string(buf[offset:]))
+			}
+		}
+		handler(string(buf), groups)
+		if !o.next() {
+			return
+		}
+	}
+}
+
+// FillTemplate replaces every #N in template with the N-th group, counted
+// from 1, like curl does for output file names. A reference to a missing
+// group is kept as it is.
+func FillTemplate(template string, groups []string) string {
+	var buf []byte
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		if c != '#' || i+1 >= len(template) || !isDigit(template[i+1]) {
+			buf = append(buf, c)
+			continue
+		}
+		j := i + 1
+		for j < len(template) && isDigit(template[j]) {
+			j++
+		}
+		if ok, n := parseInt([]byte(template[i+1 : j])); ok && n >= 1 && n <= len(groups) {
+			buf = append(buf, groups[n-1]...)
+			i = j - 1
+			continue
+		}
+		buf = append(buf, c)
+	}
+	return string(buf)
+}
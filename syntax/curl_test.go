@@ -0,0 +1,79 @@
+package syntax_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pierre-primary/go-braces/syntax"
+)
+
+func TestCurlDialect(t *testing.T) {
+	equal := DefineParseExpand(t, syntax.CurlDialect)
+	fail := DefineParseError(t, syntax.CurlDialect)
+
+	equal("http://site.{one,two}.com", E{"http://site.one.com", "http://site.two.com"})
+	equal("f[1-100:30]", E{"f1", "f31", "f61", "f91"})
+	equal("[01-10:3]", E{"01", "04", "07", "10"})
+	equal("[001-9:4]", E{"001", "005", "009"})
+	equal("[1- 3]", E{"1", "2", "3"})
+	equal("[a-z:10]", E{"a", "k", "u"})
+	equal("[A-C]{x,}", E{"Ax", "A", "Bx", "B", "Cx", "C"})
+	equal("{a}[5-5]", E{"a5"})
+	equal(`\{a,b\}\[1-2\]`, E{"{a,b}[1-2]"})
+	equal(`{a\,b,c\}}`, E{"a,b", "c}"})
+	equal(`a\b,c`, E{`a\b,c`})
+	equal("http://[::1]:80/[]{a,b}", E{"http://[::1]:80/[]a", "http://[::1]:80/[]b"})
+
+	fail("{a,{b}}", syntax.ErrNestedBrace)
+	fail("{a,[1-2]}", syntax.ErrNestedBrace)
+	fail("{a,b", syntax.ErrUnmatchedBrace)
+	fail("{}", syntax.ErrEmptyBraces)
+	fail("{a]}", syntax.ErrUnexpectedBracket)
+	fail("a}", syntax.ErrUnmatchedClose)
+	fail("a]", syntax.ErrUnmatchedClose)
+	fail("[5-1]", syntax.ErrBadRange)
+	fail("[1-5:0]", syntax.ErrBadRange)
+	fail("[1-5:5]", syntax.ErrBadRange)
+	fail("[3-3:2]", syntax.ErrBadRange)
+	fail("[a-z", syntax.ErrBadRange)
+	fail("[A-z]", syntax.ErrBadRange)
+	fail("[-1-2]", syntax.ErrBadRangeSpecification)
+	fail(strings.Repeat("{a}", 100), syntax.ErrTooManyGlobs)
+
+	// String gives a brace pattern with the same expansion, also for sets
+	// of one item
+	pattern := DefineExpand(t)
+	for _, input := range []string{"{a}[1-2]", `x{a\,b}y`, "{a,b}[01-03:2]"} {
+		exp, _ := syntax.Parse(input, syntax.CurlDialect)
+		pattern(exp.String(), exp.Expand(nil))
+	}
+}
+
+func TestFillTemplate(t *testing.T) {
+	exp, err := syntax.Parse("http://{site,host}.x/[1-2]/{a}.txt", syntax.CurlDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result []string
+	exp.WalkGroups(func(str string, groups []string) {
+		result = append(result, str+" "+syntax.FillTemplate("#1_#2#3#4#0#12.txt", groups))
+	})
+	expected := E{
+		"http://site.x/1/a.txt site_1a#4#0#12.txt",
+		"http://site.x/2/a.txt site_2a#4#0#12.txt",
+		"http://host.x/1/a.txt host_1a#4#0#12.txt",
+		"http://host.x/2/a.txt host_2a#4#0#12.txt",
+	}
+	if strings.Join(result, "\n") != strings.Join(expected, "\n") {
+		t.Fatal(result)
+	}
+
+	exp, _ = syntax.Parse("[8-9]", syntax.CurlDialect)
+	result = result[:0]
+	exp.WalkGroups(func(str string, groups []string) {
+		result = append(result, syntax.FillTemplate("#1#1", groups))
+	})
+	if strings.Join(result, " ") != "88 99" {
+		t.Fatal(result)
+	}
+}
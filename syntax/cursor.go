@@ -0,0 +1,216 @@
+package syntax
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"unicode/utf8"
+)
+
+var ErrInvalidToken = errors.New("syntax: invalid cursor token")
+
+const cursorVersion = 2
+
+// cursorMaxWidth bounds the width of the ranges read from a token, which
+// would otherwise pad every string with as many zeros as the token says.
+const cursorMaxWidth = 1 << 12
+
+// Cursor walks an expansion one string at a time. It can be paused at any
+// point, saved as a token and resumed later, possibly in another process.
+type Cursor struct {
+	exp   *BraceExp
+	flags ExpandFlags
+	x     *indexer
+	o     odometer
+	pos   int
+	done  bool
+	buf   []byte
+}
+
+// NewCursor returns a cursor positioned at the first string of the
+// expansion of exp. Only KeepEscape and KeepQuote apply.
+func NewCursor(exp *BraceExp, flags ...ExpandFlags) *Cursor {
+	var flag ExpandFlags
+	for _, f := range flags {
+		flag |= f
+	}
+	return &Cursor{
+		exp:   exp,
+		flags: flag & (KeepEscape | KeepQuote),
+		x:     newIndexer(),
+		o:     odometer{root: newState(exp)},
+	}
+}
+
+// Next returns the string at the current position and advances the cursor.
+// It returns false once the expansion is exhausted.
+func (c *Cursor) Next() (string, bool) {
+	if c.done {
+		return "", false
+	}
+	c.buf = c.o.root.append(c.buf[:0], c.flags)
+	c.pos++
+	c.done = !c.o.next()
+	return string(c.buf), true
+}
+
+// Pos returns the index of the string the next call to Next returns.
+func (c *Cursor) Pos() int {
+	return c.pos
+}
+
+// Seek moves the cursor to the pos-th string. Seeking past the end leaves
+// the cursor exhausted.
+func (c *Cursor) Seek(pos int) {
+	if pos < 0 {
+		pos = 0
+	}
+	c.pos = pos
+	if total := c.x.count(c.exp); total >= 0 && pos >= total {
+		c.done = true
+		return
+	}
+	c.done = false
+	c.o = odometer{root: newState(c.exp)}
+	c.x.seek(c.o.root, pos)
+}
+
+// Token returns a compact, URL-safe encoding of the expression, the expand
+// flags and the position of the cursor. The expression is stored as its
+// tree, so it does not depend on the flags it was parsed with. Ranges padded
+// to more than 4096 bytes cannot be resumed.
+func (c *Cursor) Token() string {
+	var buf []byte
+	buf = binary.AppendUvarint(buf, cursorVersion)
+	buf = binary.AppendUvarint(buf, uint64(c.flags))
+	buf = binary.AppendUvarint(buf, uint64(c.pos))
+	buf = appendTree(buf, c.exp)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// appendTree appends the encoding of exp: its op, then the items of a
+// concat or an alternate, the range data of a range, or the value of a
+// leaf.
+func appendTree(buf []byte, exp *BraceExp) []byte {
+	buf = append(buf, byte(exp.Op))
+	switch exp.Op {
+	case OpConcat, OpAlternate:
+		buf = binary.AppendUvarint(buf, uint64(len(exp.Subs)))
+		for _, sub := range exp.Subs {
+			buf = appendTree(buf, sub)
+		}
+	case OpIntegerRange, OpCharRange:
+		sta, num, sep, wid := rangeData(exp)
+		for _, v := range [...]int{sta, num, sep, wid, int(rangeFormat(exp))} {
+			buf = binary.AppendVarint(buf, int64(v))
+		}
+	default:
+		buf = binary.AppendUvarint(buf, uint64(len(exp.Val)))
+		buf = append(buf, exp.Val...)
+	}
+	return buf
+}
+
+// readTree decodes an expression written by appendTree and returns the
+// bytes after it.
+func readTree(buf []byte) (*BraceExp, []byte, bool) {
+	if len(buf) == 0 {
+		return nil, nil, false
+	}
+	op := Op(buf[0])
+	buf = buf[1:]
+	switch op {
+	case OpConcat, OpAlternate:
+		n, w := binary.Uvarint(buf)
+		if w <= 0 || n == 0 || n > uint64(len(buf)) {
+			return nil, nil, false
+		}
+		buf = buf[w:]
+		subs := make([]*BraceExp, n)
+		for i := range subs {
+			var ok bool
+			if subs[i], buf, ok = readTree(buf); !ok {
+				return nil, nil, false
+			}
+		}
+		if op == OpConcat {
+			return newConcat(subs...), buf, true
+		}
+		return &BraceExp{Op: OpAlternate, Subs: subs}, buf, true
+	case OpIntegerRange, OpCharRange:
+		var vals [5]int
+		for i := range vals {
+			v, w := binary.Varint(buf)
+			if w <= 0 {
+				return nil, nil, false
+			}
+			vals[i], buf = int(v), buf[w:]
+		}
+		if !validRange(op, vals[0], vals[1], vals[2], vals[3], numFormat(vals[4])) {
+			return nil, nil, false
+		}
+		return newRange(op, vals[0], vals[1], vals[2], vals[3], numFormat(vals[4])), buf, true
+	case OpEmpty, OpLiteral, OpEscape, OpQuote:
+		n, w := binary.Uvarint(buf)
+		if w <= 0 || n > uint64(len(buf)-w) || (n == 0 && (op == OpEscape || op == OpQuote)) {
+			return nil, nil, false
+		}
+		exp := &BraceExp{Op: op}
+		exp.Val = append(exp.Val0[:0], buf[w:w+int(n)]...)
+		return exp, buf[w+int(n):], true
+	}
+	return nil, nil, false
+}
+
+// validRange reports whether range data read from a token describes a
+// range the parsers could have built.
+func validRange(op Op, sta, num, sep, wid int, f numFormat) bool {
+	if num < 0 || wid < 0 || wid > cursorMaxWidth || !f.valid() {
+		return false
+	}
+	if num > 0 {
+		// the last item must not overflow
+		u := absToUint(sep)
+		if u == 0 || u > uint(MaxInt)/uint(num) {
+			return false
+		}
+		d := int(u) * num
+		if sep > 0 && sta > MaxInt-d || sep < 0 && sta < -MaxInt-1+d {
+			return false
+		}
+	}
+	if op == OpCharRange {
+		end := sta + num*sep
+		return wid == 0 && f == 0 && 0 <= min(sta, end) && max(sta, end) <= utf8.MaxRune
+	}
+	return true
+}
+
+// ResumeCursor restores a cursor saved with Token.
+func ResumeCursor(token string) (*Cursor, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var vals [3]uint64
+	for i := range vals {
+		v, w := binary.Uvarint(buf)
+		if w <= 0 {
+			return nil, ErrInvalidToken
+		}
+		vals[i], buf = v, buf[w:]
+	}
+	if vals[2] > MaxInt {
+		return nil, ErrInvalidToken
+	}
+	if vals[0] != cursorVersion {
+		return nil, ErrInvalidToken
+	}
+	exp, buf, ok := readTree(buf)
+	if !ok || len(buf) > 0 {
+		return nil, ErrInvalidToken
+	}
+	c := NewCursor(exp, ExpandFlags(vals[1]))
+	c.Seek(int(vals[2]))
+	return c, nil
+}
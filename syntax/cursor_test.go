@@ -0,0 +1,92 @@
+package syntax_test
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"testing"
+
+	"github.com/pierre-primary/go-braces/syntax"
+)
+
+func TestCursor(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		parse syntax.ParseFlags
+		flags syntax.ExpandFlags
+	}{
+		{"abc", syntax.AnyCharRange, KeepQuote},
+		{"{a,b{1..3},c}{x,y}", syntax.AnyCharRange, KeepQuote},
+		{`{Z..a}{\,,"q"}`, syntax.AnyCharRange, KeepQuote},
+		{`a"b{1..2}`, syntax.IgnoreQuote, KeepEscape},
+		{"{00..0f..3}", syntax.HexRange, 0},
+	} {
+		input := tc.input
+		exp, err := syntax.Parse(input, tc.parse)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := exp.Expand(nil, tc.flags)
+		for stop := 0; stop <= len(expected); stop++ {
+			c := syntax.NewCursor(exp, tc.flags)
+			var result []string
+			for len(result) < stop {
+				str, ok := c.Next()
+				if !ok {
+					t.Fatal("early end")
+				}
+				result = append(result, str)
+			}
+			c, err = syntax.ResumeCursor(c.Token())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.Pos() != stop {
+				t.Fatal(c.Pos())
+			}
+			for str, ok := c.Next(); ok; str, ok = c.Next() {
+				result = append(result, str)
+			}
+			if len(result) != len(expected) {
+				t.Fatal(input, stop, result)
+			}
+			for i := range result {
+				if result[i] != expected[i] {
+					t.Fatal(input, stop, result)
+				}
+			}
+		}
+	}
+
+	rangeToken := func(op syntax.Op, vals ...int64) string {
+		buf := []byte{2, 0, 0, byte(op)}
+		for _, v := range vals {
+			buf = binary.AppendVarint(buf, v)
+		}
+		return base64.RawURLEncoding.EncodeToString(buf)
+	}
+	c, err := syntax.ResumeCursor(rangeToken(syntax.OpIntegerRange, 0, 2, 5, 3, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if str, ok := c.Next(); !ok || str != "000" {
+		t.Fatal(str, ok)
+	}
+
+	for _, token := range []string{
+		"!",
+		base64.RawURLEncoding.EncodeToString([]byte("\x01\x00\x01{a,b}")),
+		base64.RawURLEncoding.EncodeToString([]byte("\x09\x00\x00{a,b}")),
+		base64.RawURLEncoding.EncodeToString([]byte("\x02\x00\x00\x07")),
+		rangeToken(syntax.OpIntegerRange, 0, 2, 5, 1<<40, 0),
+		rangeToken(syntax.OpIntegerRange, 0, 2, 5, -1, 0),
+		rangeToken(syntax.OpIntegerRange, 0, 2, 5, 0, 1<<10),
+		rangeToken(syntax.OpIntegerRange, 0, 2, 0, 0, 0),
+		rangeToken(syntax.OpIntegerRange, 1<<62, 2, 1<<61, 0, 0),
+		rangeToken(syntax.OpCharRange, 'a', 2, 1, 2, 0),
+		rangeToken(syntax.OpCharRange, 'a', 2, -100, 0, 0),
+	} {
+		if _, err := syntax.ResumeCursor(token); err != syntax.ErrInvalidToken {
+			t.Fatal(token, err)
+		}
+	}
+}
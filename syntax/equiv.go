@@ -0,0 +1,311 @@
+package syntax
+
+import (
+	"bytes"
+	"sort"
+	"unicode/utf8"
+)
+
+type Equivalence int
+
+const (
+	// SeqEquivalence requires both expansions to be the same sequence.
+	SeqEquivalence Equivalence = iota
+	// SetEquivalence requires both expansions to produce the same set of
+	// strings, regardless of order and repetition.
+	SetEquivalence
+)
+
+// progression is a run of integers or chars collected while normalizing
+// the items of an alternate.
+type progression struct {
+	op                 Op
+	sta, num, sep, wid int
+	f                  numFormat
+}
+
+func (pg *progression) last() int {
+	return pg.sta + pg.num*pg.sep
+}
+
+// push tries to extend pg with the progression q. It returns the part of q
+// that did not fit.
+func (pg *progression) push(q progression) (rest progression, ok bool) {
+	if pg.op != q.op || pg.wid != q.wid || pg.f != q.f {
+		return q, false
+	}
+	if pg.num == 0 {
+		if q.sta == pg.sta {
+			return q, false
+		}
+		pg.sep = q.sta - pg.sta
+	} else if q.sta != pg.last()+pg.sep {
+		return q, false
+	}
+	pg.num++
+	if q.num == 0 {
+		return q, true
+	}
+	if q.sep == pg.sep {
+		pg.num += q.num
+		return q, true
+	}
+	q.sta += q.sep
+	q.num--
+	if q.num == 0 {
+		q.sep = 0
+	}
+	return q, false
+}
+
+func (pg *progression) exp() *BraceExp {
+	if pg.num == 0 {
+		return newLiteral(appendRangeValue(nil, pg.op, pg.sta, pg.wid, pg.f))
+	}
+	return newRange(pg.op, pg.sta, pg.num, pg.sep, pg.wid, pg.f)
+}
+
+func appendRangeValue(buf []byte, op Op, val, wid int, f numFormat) []byte {
+	if op == OpCharRange {
+		return utf8.AppendRune(buf, rune(val))
+	}
+	return appendInt(buf, val, wid, f)
+}
+
+// asProgression reports the progression a normalized node stands for.
+// Literals holding a single integer or rune are single element progressions.
+func asProgression(exp *BraceExp) (progression, bool) {
+	switch exp.Op {
+	case OpIntegerRange, OpCharRange:
+		sta, num, sep, wid := rangeData(exp)
+		return progression{exp.Op, sta, num, sep, wid, rangeFormat(exp)}, true
+	case OpLiteral:
+		val := exp.Val
+		if len(val) == 0 {
+			break
+		}
+		if ok, num := parseInt(val); ok && val[0] != '+' {
+			wid := 0
+			if len(val) >= 2 && (val[0] == '0' || (val[0] == '-' && val[1] == '0')) {
+				wid = len(val)
+			}
+			if string(appendNumber(nil, num, wid)) == string(val) {
+				return progression{op: OpIntegerRange, sta: num, wid: wid}, true
+			}
+			break
+		}
+		if r, w := utf8.DecodeRune(val); w == len(val) && r != utf8.RuneError {
+			return progression{op: OpCharRange, sta: int(r)}, true
+		}
+	}
+	return progression{}, false
+}
+
+func normalizeAlternate(subs []*BraceExp) *BraceExp {
+	var items []*BraceExp
+	var cur progression
+	inRun := false
+	flush := func() {
+		if inRun {
+			items = append(items, cur.exp())
+			inRun = false
+		}
+	}
+	for _, sub := range subs {
+		q, ok := asProgression(sub)
+		if !ok {
+			flush()
+			items = append(items, sub)
+			continue
+		}
+		if inRun {
+			rest, fit := cur.push(q)
+			if fit {
+				continue
+			}
+			flush()
+			q = rest
+		}
+		cur, inRun = q, true
+	}
+	flush()
+	return newAlternate(items...)
+}
+
+func normalize(exp *BraceExp, flags ExpandFlags, set bool) *BraceExp {
+	switch exp.Op {
+	case OpConcat:
+		var subs []*BraceExp
+		for _, sub := range exp.Subs {
+			sub = normalize(sub, flags, set)
+			if sub.Op == OpLiteral && len(sub.Val) == 0 {
+				continue
+			}
+			if last := len(subs) - 1; last >= 0 && sub.Op == OpLiteral && subs[last].Op == OpLiteral {
+				subs[last].Val = append(subs[last].Val, sub.Val...)
+				continue
+			}
+			if sub.Op == OpConcat {
+				subs = append(subs, sub.Subs...)
+				continue
+			}
+			subs = append(subs, sub)
+		}
+		if len(subs) == 0 {
+			return newLiteral(nil)
+		}
+		return newConcat(subs...)
+	case OpAlternate:
+		subs := make([]*BraceExp, 0, len(exp.Subs))
+		for _, sub := range exp.Subs {
+			sub = normalize(sub, flags, set)
+			if sub.Op == OpAlternate {
+				subs = append(subs, sub.Subs...)
+			} else {
+				subs = append(subs, sub)
+			}
+		}
+		if set {
+			subs = sortItems(subs)
+		}
+		return normalizeAlternate(subs)
+	case OpIntegerRange, OpCharRange:
+		sta, num, sep, wid := rangeData(exp)
+		if set && sep < 0 {
+			sta, sep = sta+num*sep, -sep
+		}
+		pg := progression{exp.Op, sta, num, sep, wid, rangeFormat(exp)}
+		return pg.exp()
+	default:
+		return newLiteral(appendConst(nil, exp, flags))
+	}
+}
+
+// sortItems orders alternate items so that sets built differently end up
+// with the same layout. Progressions come first, by kind, padding and
+// start; the remaining items are ordered by pattern and deduplicated.
+func sortItems(subs []*BraceExp) []*BraceExp {
+	type item struct {
+		exp *BraceExp
+		pg  progression
+		ok  bool
+		key string
+	}
+	items := make([]item, len(subs))
+	for i, sub := range subs {
+		pg, ok := asProgression(sub)
+		items[i] = item{exp: sub, pg: pg, ok: ok}
+		if !ok {
+			items[i].key = sub.String()
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := &items[i], &items[j]
+		if a.ok != b.ok {
+			return a.ok
+		}
+		if !a.ok {
+			return a.key < b.key
+		}
+		if a.pg.op != b.pg.op {
+			return a.pg.op < b.pg.op
+		}
+		if a.pg.wid != b.pg.wid {
+			return a.pg.wid < b.pg.wid
+		}
+		return a.pg.sta < b.pg.sta
+	})
+	subs = subs[:0]
+	for i := range items {
+		if i > 0 && items[i].exp.Equal(items[i-1].exp) {
+			continue
+		}
+		subs = append(subs, items[i].exp)
+	}
+	return subs
+}
+
+// equalSeq compares the expansions of a and b in order. Plain and leftmost
+// walks are compared a string at a time with two odometers; sorted and
+// unique walks need the whole expansion of a.
+func equalSeq(a, b *BraceExp, flags ExpandFlags) bool {
+	if flags&Unique == 0 && a.Count() != b.Count() {
+		return false
+	}
+	if flags&(Sorted|NaturalSorted|Unique) == 0 {
+		oa, ob := seqOdometer(a, flags), seqOdometer(b, flags)
+		var bufA, bufB []byte
+		for {
+			bufA = oa.root.append(bufA[:0], flags)
+			bufB = ob.root.append(bufB[:0], flags)
+			if !bytes.Equal(bufA, bufB) {
+				return false
+			}
+			if na, nb := oa.next(), ob.next(); !na || !nb {
+				return na == nb
+			}
+		}
+	}
+	data := a.Expand(nil, flags)
+	i, ok := 0, true
+	b.Walk(func(str string) {
+		if ok = ok && i < len(data) && data[i] == str; ok {
+			i++
+		}
+	}, flags)
+	return ok && i == len(data)
+}
+
+// seqOdometer returns an odometer walking exp in the order Walk uses with
+// flags, which must not sort.
+func seqOdometer(exp *BraceExp, flags ExpandFlags) *odometer {
+	if flags&LeftmostFastest != 0 {
+		return &odometer{root: newState(exp), order: groupOrder(exp, nil, true), leftmost: true}
+	}
+	return &odometer{root: newState(exp)}
+}
+
+func equalSet(a, b *BraceExp, flags ExpandFlags) bool {
+	seen := make(map[string]bool)
+	a.Walk(func(str string) { seen[str] = false }, flags)
+	ok := true
+	b.Walk(func(str string) {
+		if _, found := seen[str]; !found {
+			ok = false
+		} else {
+			seen[str] = true
+		}
+	}, flags)
+	if !ok {
+		return false
+	}
+	for _, hit := range seen {
+		if !hit {
+			return false
+		}
+	}
+	return true
+}
+
+// Equivalent reports whether a and b expand to the same strings. Both trees
+// are normalized first, so ranges, literal lists, escapes and quotes that
+// spell the same items compare equal without expansion; only when the
+// normalized trees differ are the expansions compared, after their sizes
+// and, unless they are sorted or made unique, a string at a time.
+func Equivalent(a, b *BraceExp, level Equivalence, flags ...ExpandFlags) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	var flag ExpandFlags
+	for _, f := range flags {
+		flag |= f
+	}
+	set := level == SetEquivalence
+	if normalize(a, flag, set).Equal(normalize(b, flag, set)) {
+		return true
+	}
+	if set {
+		return equalSet(a, b, flag)
+	}
+	return equalSeq(a, b, flag)
+}
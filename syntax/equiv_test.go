@@ -0,0 +1,59 @@
+package syntax_test
+
+import (
+	"testing"
+
+	"github.com/pierre-primary/go-braces/syntax"
+)
+
+func DefineEquivalent(t *testing.T) func(string, string, syntax.Equivalence, bool) {
+	return func(a, b string, level syntax.Equivalence, expected bool) {
+		x, err := syntax.Parse(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		y, err := syntax.Parse(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if syntax.Equivalent(x, y, level) != expected {
+			t.Fatal(a, b, level, !expected)
+		}
+	}
+}
+
+func TestEquivalent(t *testing.T) {
+	equal := DefineEquivalent(t)
+	seq, set := syntax.SeqEquivalence, syntax.SetEquivalence
+
+	equal("aaaa", "aaaa", seq, true)
+	equal("aaaa", "aaa", seq, false)
+	equal("aaaa", "bbbb", seq, false)
+	equal("{1..9}", "{1..9}", seq, true)
+	equal("{1..9}", "{1..9..1}", seq, true)
+	equal("{1..9}", "{1..9..2}", seq, false)
+	equal("{1..2}", "{1,2}", seq, true)
+	equal("a{1..2}b", "a{1,2}b", seq, true)
+	equal("a{1..2}b", "a{1..2}", seq, false)
+	equal("{1..6}", "{1,2,{3..5},6}", seq, true)
+	equal("{1..9..2}", "{1,3..9..2}", seq, false)
+	equal("{1..9..2}", "{1,{3..9..2}}", seq, true)
+	equal("{01..03}", "{01,02,03}", seq, true)
+	equal("{01..03}", "{1..3}", seq, false)
+	equal("{a..c}", "{a,b,c}", seq, true)
+	equal(`\a\b`, "ab", seq, true)
+	equal(`"ab"`, "ab", seq, true)
+	equal("a{b,c}", "{ab,ac}", seq, true)
+
+	// large expansions are told apart by their size or their first strings
+	equal("{1..100000000}", "{1..99999999}", seq, false)
+	equal("x{1..100000000}", "y{1..100000000}", seq, false)
+	equal("{1..3}{a,b}", "{1a,1b,2a,2b,3a,3b}", seq, true)
+
+	equal("{1..3}", "{3..1}", seq, false)
+	equal("{1..3}", "{3..1}", set, true)
+	equal("{1..3}", "{3,1,2}", set, true)
+	equal("{a,b,a}", "{b,a}", set, true)
+	equal("{a,b}{1..2}", "{b1,a2,a1,b2}", set, true)
+	equal("{a,b}", "{a,c}", set, false)
+}
@@ -1,5 +1,9 @@
 package syntax
 
+import (
+	"errors"
+)
+
 type Error struct {
 	Msg string
 	idx int
@@ -12,3 +16,5 @@ func (e *Error) Error() string {
 	buf = appendNumber(buf, e.idx, 0)
 	return string(buf)
 }
+
+var ErrOverflow = errors.New("syntax: expansion size overflows int")
@@ -71,7 +71,7 @@ func (n *BraceExp) link(next *BraceExp) {
 func printExp(exp *BraceExp, deepth int) {
 	switch exp.Op {
 	case OpCharRange:
-		rg := unsafe.Slice((*int)(unsafe.Pointer(&exp.Val[0])), 4)
+		rg := unsafe.Slice((*int)(unsafe.Pointer(&exp.Val[0])), 5)
 		sta, num, sep := rg[0], rg[1], rg[2]
 		s := fmt.Sprintf("%c..%c", rune(sta), rune(sta+num*sep))
 		if sep > 1 || sep < -1 {
@@ -80,8 +80,8 @@ func printExp(exp *BraceExp, deepth int) {
 		fmt.Printf("%*s - %s (\"%s\")\n", deepth<<1, "", exp.Op, s)
 	case OpIntegerRange:
 		rg := unsafe.Slice((*int)(unsafe.Pointer(&exp.Val[0])), 5)
-		sta, num, sep, wid := rg[0], rg[1], rg[2], rg[3]
-		s := fmt.Sprintf("%*d..%*d", wid, sta, wid, sta+num*sep)
+		sta, num, sep, wid, f := rg[0], rg[1], rg[2], rg[3], numFormat(rg[4])
+		s := fmt.Sprintf("%s..%s", appendInt(nil, sta, wid, f), appendInt(nil, sta+num*sep, wid, f))
 		if sep > 1 || sep < -1 {
 			s = fmt.Sprintf("%s..%d", s, sep)
 		}
@@ -130,12 +130,55 @@ func (n *BraceExp) Equal(t *BraceExp) bool {
 	return true
 }
 
+// walkExp walks n as selected by flags. tick, if set, is called for every
+// string the underlying walk produces, before any sorting or filtering.
+func walkExp(n *BraceExp, flag ExpandFlags, handler WalkBytesHandler, buffer []byte, tick func()) []byte {
+	exp := n
+	if flag&(Sorted|NaturalSorted) != 0 {
+		var ok bool
+		if exp, ok = sortedExp(n, flag); !ok {
+			return walkSorted(exp, flag, handler, buffer, tick)
+		}
+	}
+	if flag&Unique != 0 && !isUnique(exp, flag) {
+		handler = uniqueHandler(handler)
+	}
+	if tick != nil {
+		handler = tickHandler(handler, tick)
+	}
+	if flag&LeftmostFastest != 0 && flag&(Sorted|NaturalSorted) == 0 {
+		return walkOrdered(exp, flag, nil, handler, buffer)
+	}
+	return walk(exp, flag, handler, buffer)
+}
+
+func tickHandler(handler WalkBytesHandler, tick func()) WalkBytesHandler {
+	return func(b []byte) {
+		tick()
+		handler(b)
+	}
+}
+
 func (n *BraceExp) WalkWithBuffer(handler WalkHandler, buffer []byte, flags ...ExpandFlags) []byte {
 	var flag ExpandFlags
 	for _, f := range flags {
 		flag |= f
 	}
-	return walk(n, flag, handler, buffer)
+	return walkExp(n, flag, stringHandler(handler), buffer, nil)
+}
+
+// WalkBytesWithBuffer is like WalkWithBuffer but hands out the walk buffer
+// instead of a string, so no allocation is made per result.
+func (n *BraceExp) WalkBytesWithBuffer(handler WalkBytesHandler, buffer []byte, flags ...ExpandFlags) []byte {
+	var flag ExpandFlags
+	for _, f := range flags {
+		flag |= f
+	}
+	return walkExp(n, flag, handler, buffer, nil)
+}
+
+func (n *BraceExp) WalkBytes(handler WalkBytesHandler, flags ...ExpandFlags) {
+	n.WalkBytesWithBuffer(handler, nil, flags...)
 }
 
 func (n *BraceExp) ExpandWithBuffer(data []string, buffer []byte, flags ...ExpandFlags) ([]string, []byte) {
@@ -7,6 +7,14 @@ import (
 
 type WalkHandler func(str string)
 
+// WalkBytesHandler receives the walk buffer itself. b is only valid during
+// the call and must not be modified.
+type WalkBytesHandler func(b []byte)
+
+func stringHandler(handler WalkHandler) WalkBytesHandler {
+	return func(b []byte) { handler(string(b)) }
+}
+
 var ZEROS = [8]byte{'0', '0', '0', '0', '0', '0', '0', '0'}
 
 type ExpandFlags uint16
@@ -14,9 +22,13 @@ type ExpandFlags uint16
 const (
 	KeepEscape ExpandFlags = 1 << iota
 	KeepQuote
+	Unique          // drop repeated strings, keeping the first one
+	Sorted          // produce strings in byte-wise order
+	NaturalSorted   // produce strings in natural order, see NaturalCompare
+	LeftmostFastest // vary the leftmost brace group fastest
 )
 
-func walkAlternate(exp *BraceExp, flags ExpandFlags, handler WalkHandler, buffer []byte) []byte {
+func walkAlternate(exp *BraceExp, flags ExpandFlags, handler WalkBytesHandler, buffer []byte) []byte {
 	offset := len(buffer)
 	for _, item := range exp.Subs {
 		buffer = walk(item, flags, handler, buffer[:offset])
@@ -24,8 +36,8 @@ func walkAlternate(exp *BraceExp, flags ExpandFlags, handler WalkHandler, buffer
 	return buffer
 }
 
-func walkCharRange(exp *BraceExp, flags ExpandFlags, handler WalkHandler, buffer []byte) []byte {
-	rg := unsafe.Slice((*int)(unsafe.Pointer(&exp.Val[0])), 4)
+func walkCharRange(exp *BraceExp, flags ExpandFlags, handler WalkBytesHandler, buffer []byte) []byte {
+	rg := unsafe.Slice((*int)(unsafe.Pointer(&exp.Val[0])), 5)
 	sta, num, sep := rg[0], rg[1], rg[2]
 
 	offset := len(buffer)
@@ -37,36 +49,36 @@ func walkCharRange(exp *BraceExp, flags ExpandFlags, handler WalkHandler, buffer
 	return buffer
 }
 
-func walkIntegerRange(exp *BraceExp, flags ExpandFlags, handler WalkHandler, buffer []byte) []byte {
-	rg := unsafe.Slice((*int)(unsafe.Pointer(&exp.Val[0])), 4)
-	sta, num, sep, wid := rg[0], rg[1], rg[2], rg[3]
+func walkIntegerRange(exp *BraceExp, flags ExpandFlags, handler WalkBytesHandler, buffer []byte) []byte {
+	rg := unsafe.Slice((*int)(unsafe.Pointer(&exp.Val[0])), 5)
+	sta, num, sep, wid, f := rg[0], rg[1], rg[2], rg[3], numFormat(rg[4])
 
 	offset := len(buffer)
-	buffer = walk(exp.Next, flags, handler, appendNumber(buffer, sta, wid))
+	buffer = walk(exp.Next, flags, handler, appendInt(buffer, sta, wid, f))
 	for i := 0; i < num; i++ {
 		sta += sep
-		buffer = walk(exp.Next, flags, handler, appendNumber(buffer[:offset], sta, wid))
+		buffer = walk(exp.Next, flags, handler, appendInt(buffer[:offset], sta, wid, f))
 	}
 	return buffer
 }
 
-func walkEscape(exp *BraceExp, flags ExpandFlags, handler WalkHandler, buffer []byte) []byte {
+func walkEscape(exp *BraceExp, flags ExpandFlags, handler WalkBytesHandler, buffer []byte) []byte {
 	if flags&KeepEscape == 0 {
 		return walk(exp.Next, flags, handler, append(buffer, exp.Val[1:]...))
 	}
 	return walk(exp.Next, flags, handler, append(buffer, exp.Val...))
 }
 
-func walkQuote(exp *BraceExp, flags ExpandFlags, handler WalkHandler, buffer []byte) []byte {
+func walkQuote(exp *BraceExp, flags ExpandFlags, handler WalkBytesHandler, buffer []byte) []byte {
 	if flags&KeepQuote == 0 {
 		return walk(exp.Next, flags, handler, buffer)
 	}
 	return walk(exp.Next, flags, handler, append(buffer, exp.Val...))
 }
 
-func walk(exp *BraceExp, flags ExpandFlags, handler WalkHandler, buffer []byte) []byte {
+func walk(exp *BraceExp, flags ExpandFlags, handler WalkBytesHandler, buffer []byte) []byte {
 	if exp == nil {
-		handler(string(buffer))
+		handler(buffer)
 		return buffer
 	}
 	switch exp.Op {
@@ -1,6 +1,7 @@
 package syntax_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/pierre-primary/go-braces/syntax"
@@ -11,6 +12,10 @@ type E = []string
 const (
 	KeepEscape = syntax.KeepEscape
 	KeepQuote  = syntax.KeepQuote
+	Unique     = syntax.Unique
+	Sorted     = syntax.Sorted
+	Natural    = syntax.NaturalSorted
+	Leftmost   = syntax.LeftmostFastest
 )
 
 //go:noinline
@@ -41,21 +46,92 @@ func BenchmarkExpand(t *testing.B) {
 	t.Run("Unicode", expand("{你好吗,你在吗,你在哪}"))
 }
 
+//go:noinline
+func discardBytes(b []byte) {}
+
+func BenchmarkWalkBytes(t *testing.B) {
+	expand := func(input string) func(t *testing.B) {
+		return func(t *testing.B) {
+			exp, err := syntax.Parse(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			buffer := exp.WalkBytesWithBuffer(discardBytes, nil)
+			t.ReportAllocs()
+			t.ResetTimer()
+			for i := 0; i < t.N; i++ {
+				buffer = exp.WalkBytesWithBuffer(discardBytes, buffer)
+			}
+		}
+	}
+	t.Run("Literal", expand("abcdefg"))
+	t.Run("Alternate", expand("{abc,def,ghi}"))
+	t.Run("Mixed", expand("aaa{abc,def,ghi}bbb"))
+	t.Run("CharRange:[0-9]", expand("{0..9}"))
+	t.Run("CharRange:[a-z]", expand("{a..z}"))
+	t.Run("CharRange:[A-Z]", expand("{A..Z}"))
+	t.Run("IntRange:10", expand("{1..10}"))
+	t.Run("IntRange:100", expand("{1..100}"))
+	t.Run("Unicode", expand("{你好吗,你在吗,你在哪}"))
+}
+
+func TestWalkBytesAllocs(t *testing.T) {
+	exp, err := syntax.Parse("aaa{abc,def,ghi}{1..100}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buffer := exp.WalkBytesWithBuffer(discardBytes, nil)
+	allocs := testing.AllocsPerRun(10, func() {
+		buffer = exp.WalkBytesWithBuffer(discardBytes, buffer)
+	})
+	if allocs != 0 {
+		t.Fatal(allocs)
+	}
+}
+
 func DefineExpand(t *testing.T) func(string, []string, ...syntax.ExpandFlags) {
+	return DefineParseExpand(t, 0)
+}
+
+// DefineParseExpand is DefineExpand for patterns parsed with flags.
+func DefineParseExpand(t *testing.T, flags syntax.ParseFlags) func(string, []string, ...syntax.ExpandFlags) {
+	return defineExpandWith(t, func(input string) (*syntax.BraceExp, error) {
+		return syntax.Parse(input, flags)
+	})
+}
+
+// DefineSyntaxExpand is DefineExpand for patterns written with the tokens
+// of s and parsed with flags.
+func DefineSyntaxExpand(t *testing.T, s syntax.Syntax, flags syntax.ParseFlags) func(string, []string, ...syntax.ExpandFlags) {
+	return defineExpandWith(t, func(input string) (*syntax.BraceExp, error) {
+		return syntax.ParseWithSyntax(input, s, flags)
+	})
+}
+
+// DefineParseError returns a check that parsing with flags fails with an
+// error containing msg.
+func DefineParseError(t *testing.T, flags syntax.ParseFlags) func(string, string) {
+	return func(input string, msg string) {
+		_, err := syntax.Parse(input, flags)
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Fatal(input, err)
+		}
+	}
+}
+
+func defineExpandWith(t *testing.T, parse func(string) (*syntax.BraceExp, error)) func(string, []string, ...syntax.ExpandFlags) {
 	return func(input string, expected []string, flags ...syntax.ExpandFlags) {
-		exp, err := syntax.Parse(input)
+		exp, err := parse(input)
 		if err != nil {
-			t.Fatal(err)
+			t.Fatal(input, err)
 		}
 		result := exp.Expand(nil, flags...)
 		if len(result) != len(expected) {
-			t.Fatal(result)
-			return
+			t.Fatal(input, result)
 		}
 		for i := range result {
 			if result[i] != expected[i] {
-				t.Fatal(result)
-				return
+				t.Fatal(input, result)
 			}
 		}
 	}
@@ -169,6 +245,58 @@ func TestCharRange(t *testing.T) {
 	equal("{b..a}", E{"b", "a"})
 }
 
+func TestRadixRange(t *testing.T) {
+	equal := func(input string, flags syntax.ParseFlags, expected []string) {
+		DefineParseExpand(t, flags)(input, expected)
+		exp, _ := syntax.Parse(input, flags)
+		result := exp.Expand(nil)
+		// every reader of the range data agrees with the walk
+		for i, str := range result {
+			if nth := exp.Nth(i); nth != str {
+				t.Fatal(input, i, nth)
+			}
+		}
+		if again, _ := syntax.Parse(exp.String(), flags); strings.Join(again.Expand(nil), " ") != strings.Join(result, " ") {
+			t.Fatal(input, exp.String())
+		}
+	}
+
+	equal("{0x00..0x0b..5}", 0, E{"0x00", "0x05", "0x0a"})
+	equal("{0x0..0xC..4}", 0, E{"0x0", "0x4", "0x8", "0xC"})
+	equal("{0X0f..0X11}", 0, E{"0X0f", "0X10", "0X11"})
+	equal("r{0xff..0xfd}", 0, E{"r0xff", "r0xfe", "r0xfd"})
+	equal("{0o0..0o17..5}", 0, E{"0o0", "0o5", "0o12", "0o17"})
+	equal("{-0o2..0o1}", 0, E{"-0o2", "-0o1", "0o0", "0o1"})
+	equal("{00..ff..80}", syntax.HexRange, E{"00", "80"})
+	equal("{00..ff..50}", syntax.HexRange, E{"00", "50", "a0", "f0"})
+	equal("{9..B}", syntax.HexRange, E{"9", "A", "B"})
+	equal("{a..f..2}", syntax.HexRange, E{"a", "c", "e"})
+	equal("{0..10}", syntax.HexRange, E{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9", "a", "b", "c", "d", "e", "f", "10"})
+
+	// the step is written in the base of the bounds
+	equal("{0x0..0x10..0x4}", 0, E{"0x0", "0x4", "0x8", "0xc", "0x10"})
+	equal("{0x0..0x20..10}", 0, E{"0x0", "0x10", "0x20"})
+	equal("{0o0..0o20..0o10}", 0, E{"0o0", "0o10", "0o20"})
+	equal("{0o0..0o7..8}", 0, E{"{0o0..0o7..8}"})
+	equal("{0x0..0x3..0o2}", 0, E{"{0x0..0x3..0o2}"})
+
+	// mixed bases, prefixes or cases are not ranges
+	equal("{0x1..0o7}", 0, E{"{0x1..0o7}"})
+	equal("{0x1..0X3}", 0, E{"{0x1..0X3}"})
+	equal("{0xa..0xB}", 0, E{"{0xa..0xB}"})
+	equal("{0o1..0o8}", 0, E{"{0o1..0o8}"})
+	equal("{00..ff}", 0, E{"{00..ff}"})
+	equal("{0x0..0x3}", syntax.JSCompat, E{"{0x0..0x3}"})
+
+	exp, _ := syntax.Parse("x{0x0f..0xff..10}y")
+	if s := exp.CommonSuffix(); s != "fy" {
+		t.Fatal(s)
+	}
+	if base, upper, prefix := exp.Subs[1].IntFormat(); base != 16 || upper || prefix != "0x" {
+		t.Fatal(base, upper, prefix)
+	}
+}
+
 func TestEscape(t *testing.T) {
 	equal := DefineExpand(t)
 
@@ -193,3 +321,165 @@ func TestEscape(t *testing.T) {
 	equal(`{a..b\..3}`, E{"{a..b..3}"})
 	equal(`{a..b\..3}`, E{`{a..b\..3}`}, KeepEscape)
 }
+
+func TestUnique(t *testing.T) {
+	equal := DefineExpand(t)
+
+	equal("{a,a,b}", E{"a", "b"}, Unique)
+	equal("{1..3}{,}", E{"1", "2", "3"}, Unique)
+	equal("{a,b}{1..2}", E{"a1", "a2", "b1", "b2"}, Unique)
+	equal("{a,ab}{,b}", E{"a", "ab", "abb"}, Unique)
+	equal("{b..a}{a..b}", E{"ba", "bb", "aa", "ab"}, Unique)
+	equal(`{a,\a,"a"}`, E{"a"}, Unique)
+	equal(`{a,\a,"a"}`, E{"a", `\a`, `"a"`}, Unique, KeepEscape, KeepQuote)
+}
+
+func TestSorted(t *testing.T) {
+	equal := DefineExpand(t)
+
+	equal("{b,a,c}", E{"a", "b", "c"}, Sorted)
+	equal("{3..1}", E{"1", "2", "3"}, Sorted)
+	equal("{c..a}{2..1}", E{"a1", "a2", "b1", "b2", "c1", "c2"}, Sorted)
+	equal("web{8..11}", E{"web10", "web11", "web8", "web9"}, Sorted)
+	equal("web{8..11}", E{"web8", "web9", "web10", "web11"}, Natural)
+	equal("web{11..8}.dc{2,1}", E{
+		"web8.dc1", "web8.dc2", "web9.dc1", "web9.dc2",
+		"web10.dc1", "web10.dc2", "web11.dc1", "web11.dc2",
+	}, Natural)
+	equal("{a,ab}{,b}", E{"a", "ab", "ab", "abb"}, Sorted)
+	equal("{a,ab}{,b}", E{"a", "ab", "abb"}, Sorted, Unique)
+	equal("{-1..1}", E{"-1", "0", "1"}, Sorted)
+}
+
+func TestNaturalCompare(t *testing.T) {
+	less := func(a, b string) {
+		if syntax.NaturalCompare(a, b) >= 0 || syntax.NaturalCompare(b, a) <= 0 {
+			t.Fatal(a, b)
+		}
+	}
+	less("a", "b")
+	less("web2", "web10")
+	less("web2x", "web10")
+	less("01", "1")
+	less("a1b2", "a1b10")
+	less("x", "x0")
+}
+
+func TestWalkOrder(t *testing.T) {
+	equal := DefineExpand(t)
+
+	equal("{a,b}{1..3}", E{"a1", "b1", "a2", "b2", "a3", "b3"}, Leftmost)
+	equal("x{a,b}-{1,2}{,y}", E{
+		"xa-1", "xb-1", "xa-2", "xb-2", "xa-1y", "xb-1y", "xa-2y", "xb-2y",
+	}, Leftmost)
+	equal("{a{1,2},b}{x,y}", E{"a1x", "a2x", "bx", "a1y", "a2y", "by"}, Leftmost)
+	equal("{a,b}{1..2}", E{"a1", "a2", "b1", "b2"}, Leftmost, Sorted)
+
+	priority := func(input string, priority []int, expected []string) {
+		exp, err := syntax.Parse(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var result []string
+		exp.WalkPriority(func(str string) { result = append(result, str) }, priority)
+		if len(result) != len(expected) {
+			t.Fatal(result)
+		}
+		for i := range result {
+			if result[i] != expected[i] {
+				t.Fatal(result)
+			}
+		}
+	}
+	priority("{a,b}{1,2}{x,y}", []int{1, 0, 2}, E{
+		"a1x", "a2x", "b1x", "b2x", "a1y", "a2y", "b1y", "b2y",
+	})
+	priority("{a,b}{1,2}{x,y}", []int{1}, E{
+		"a1x", "a2x", "a1y", "a2y", "b1x", "b2x", "b1y", "b2y",
+	})
+	priority("{a,b}", nil, E{"a", "b"})
+
+	// the buffer prefix is kept like in the default order
+	exp, _ := syntax.Parse("{a,b}{1,2}")
+	for _, flags := range [][]syntax.ExpandFlags{nil, {Leftmost}, {Sorted}} {
+		var result []string
+		exp.WalkWithBuffer(func(str string) { result = append(result, str) }, []byte("p/"), flags...)
+		if len(result) != 4 || !strings.HasPrefix(result[0], "p/") || !strings.HasPrefix(result[3], "p/") {
+			t.Fatal(flags, result)
+		}
+	}
+}
+
+func TestExpandArena(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"{,a}",
+		"aaa{abc,def,ghi}{-120..1200..7}",
+		"{0..9223372036854775806..1000000000000000000}",
+		"{-9223372036854775808..-9223372036854775800}",
+		"{a..z}{你..好}{😀..😃}",
+		`{\a,"b"}`,
+	} {
+		exp, err := syntax.Parse(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, flags := range [][]syntax.ExpandFlags{nil, {KeepEscape, KeepQuote}, {Sorted}} {
+			expected := exp.Expand(nil, flags...)
+			result := exp.ExpandArena(nil, flags...)
+			if len(result) != len(expected) {
+				t.Fatal(input, result)
+			}
+			for i := range result {
+				if result[i] != expected[i] {
+					t.Fatal(input, result)
+				}
+			}
+		}
+	}
+
+	exp, _ := syntax.Parse("{a..z}{0..9999}")
+	allocs := testing.AllocsPerRun(10, func() { exp.ExpandArena(nil) })
+	if allocs > 6 {
+		t.Fatal(allocs)
+	}
+}
+
+func TestLengths(t *testing.T) {
+	lengths := func(input string, flags ...syntax.ExpandFlags) {
+		exp, err := syntax.Parse(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		min, max, total := exp.Lengths(flags...)
+		emin, emax, etotal := -1, 0, 0
+		exp.Walk(func(str string) {
+			if emin < 0 || len(str) < emin {
+				emin = len(str)
+			}
+			if len(str) > emax {
+				emax = len(str)
+			}
+			etotal += len(str)
+		}, flags...)
+		if min != emin || max != emax || total != etotal {
+			t.Fatal(input, min, max, total, emin, emax, etotal)
+		}
+	}
+	lengths("abc")
+	lengths("{a,bcd}{,e}")
+	lengths("{-12..105..3}")
+	lengths("{-003..0099}")
+	lengths("x{z..a..5}{~..é}")
+	lengths(`"q"\e{1..10}`)
+	lengths(`"q"\e{1..10}`, KeepEscape, KeepQuote)
+
+	lengths("{0x0..0x1ff..7}")
+	lengths("{-0o17..0o100}")
+	lengths("{-0X00F..0X100}")
+
+	exp, _ := syntax.Parse("{0..999999}{0..999999}{0..999999}{0..999999}")
+	if min, max, total := exp.Lengths(); min != 4 || max != 24 || total != -1 {
+		t.Fatal(min, max, total)
+	}
+}
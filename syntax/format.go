@@ -0,0 +1,111 @@
+package syntax
+
+import (
+	"unicode/utf8"
+)
+
+func isSpecial(b byte) bool {
+	switch b {
+	case '\\', '{', '}', ',', '"', '\'':
+		return true
+	}
+	return false
+}
+
+func appendLiteral(buf []byte, val []byte) []byte {
+	for _, b := range val {
+		if isSpecial(b) {
+			buf = append(buf, '\\')
+		}
+		buf = append(buf, b)
+	}
+	return buf
+}
+
+func appendRangeChar(buf []byte, r rune) []byte {
+	if r < utf8.RuneSelf && (isSpecial(byte(r)) || r == '.') {
+		buf = append(buf, '\\')
+	}
+	return utf8.AppendRune(buf, r)
+}
+
+// appendPattern writes exp as pattern text. que is the quote character
+// opened by a preceding sibling, text inside quotes is written verbatim.
+func appendPattern(buf []byte, exp *BraceExp, que byte) ([]byte, byte) {
+	switch exp.Op {
+	case OpConcat:
+		for _, sub := range exp.Subs {
+			buf, que = appendPattern(buf, sub, que)
+		}
+	case OpAlternate:
+		if len(exp.Subs) == 1 {
+			// a set of one item, as curl builds, is not a brace expansion
+			buf, _ = appendPattern(buf, exp.Subs[0], 0)
+			break
+		}
+		buf = append(buf, '{')
+		for i, sub := range exp.Subs {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf, _ = appendPattern(buf, sub, 0)
+		}
+		buf = append(buf, '}')
+	case OpIntegerRange:
+		sta, num, sep, wid := rangeData(exp)
+		f := rangeFormat(exp)
+		buf = append(buf, '{')
+		buf = appendInt(buf, sta, wid, f)
+		buf = append(buf, ".."...)
+		buf = appendInt(buf, sta+num*sep, wid, f)
+		if sep > 1 || sep < -1 {
+			buf = append(buf, ".."...)
+			buf = appendInt(buf, int(absToUint(sep)), 0, f)
+		}
+		buf = append(buf, '}')
+	case OpCharRange:
+		sta, num, sep, _ := rangeData(exp)
+		buf = append(buf, '{')
+		buf = appendRangeChar(buf, rune(sta))
+		buf = append(buf, ".."...)
+		buf = appendRangeChar(buf, rune(sta+num*sep))
+		if sep > 1 || sep < -1 {
+			buf = append(buf, ".."...)
+			buf = appendNumber(buf, int(absToUint(sep)), 0)
+		}
+		buf = append(buf, '}')
+	case OpLiteral:
+		if que > 0 {
+			buf = append(buf, exp.Val...)
+		} else {
+			buf = appendLiteral(buf, exp.Val)
+		}
+	case OpQuote:
+		if que == 0 {
+			que = exp.Val[0]
+		} else if que == exp.Val[0] {
+			que = 0
+		}
+		buf = append(buf, exp.Val...)
+	case OpEscape:
+		buf = append(buf, exp.Val...)
+	}
+	return buf, que
+}
+
+// AppendPattern appends the brace pattern of n to buf.
+// Char ranges whose bounds are not letters of the same case can only be
+// parsed back with AnyCharRange, and integer ranges of bare hex digits with
+// HexRange.
+func (n *BraceExp) AppendPattern(buf []byte) []byte {
+	if n == nil {
+		return buf
+	}
+	buf, _ = appendPattern(buf, n, 0)
+	return buf
+}
+
+// String returns a brace pattern that expands to the same result as n.
+func (n *BraceExp) String() string {
+	return string(n.AppendPattern(nil))
+}
@@ -0,0 +1,195 @@
+package syntax
+
+import (
+	"iter"
+	"math/bits"
+)
+
+// indexer gives random access to the strings of an expansion. Items are
+// numbered in walk order; subtree sizes are cached per node.
+type indexer struct {
+	counts map[*BraceExp]int
+}
+
+func newIndexer() *indexer {
+	return &indexer{counts: make(map[*BraceExp]int)}
+}
+
+func mulInt(a, b int) (ok bool, n int) {
+	hi, lo := bits.Mul64(uint64(a), uint64(b))
+	if hi != 0 || lo > MaxInt {
+		return false, 0
+	}
+	return true, int(lo)
+}
+
+// count returns the number of strings produced by exp, or -1 if that number
+// does not fit in an int.
+func (x *indexer) count(exp *BraceExp) int {
+	if n, ok := x.counts[exp]; ok {
+		return n
+	}
+	n := 1
+	switch exp.Op {
+	case OpConcat:
+		for _, sub := range exp.Subs {
+			c := x.count(sub)
+			var ok bool
+			if c < 0 {
+				n = -1
+				break
+			}
+			if ok, n = mulInt(n, c); !ok {
+				n = -1
+				break
+			}
+		}
+	case OpAlternate:
+		n = 0
+		for _, sub := range exp.Subs {
+			c := x.count(sub)
+			if c < 0 || n > MaxInt-c {
+				n = -1
+				break
+			}
+			n += c
+		}
+	case OpIntegerRange, OpCharRange:
+		_, num, _, _ := rangeData(exp)
+		n = num + 1
+	}
+	x.counts[exp] = n
+	return n
+}
+
+// appendNth appends the i-th string of exp to buf. i must be less than
+// count(exp).
+func (x *indexer) appendNth(buf []byte, exp *BraceExp, i int, flags ExpandFlags) []byte {
+	switch exp.Op {
+	case OpConcat:
+		// the rightmost part changes fastest
+		var a [8]int
+		idx := a[:0]
+		for k := len(exp.Subs) - 1; k >= 0; k-- {
+			if c := x.count(exp.Subs[k]); c > 0 {
+				idx = append(idx, i%c)
+				i /= c
+			} else {
+				idx = append(idx, i)
+				i = 0
+			}
+		}
+		for k, sub := range exp.Subs {
+			buf = x.appendNth(buf, sub, idx[len(idx)-1-k], flags)
+		}
+		return buf
+	case OpAlternate:
+		for _, sub := range exp.Subs {
+			c := x.count(sub)
+			if c < 0 || i < c {
+				return x.appendNth(buf, sub, i, flags)
+			}
+			i -= c
+		}
+		return buf
+	case OpIntegerRange, OpCharRange:
+		sta, _, sep, wid := rangeData(exp)
+		return appendRangeValue(buf, exp.Op, sta+i*sep, wid, rangeFormat(exp))
+	default:
+		return appendConst(buf, exp, flags)
+	}
+}
+
+// Count returns the number of strings n expands to, duplicates included, or
+// -1 if that number overflows an int.
+func (n *BraceExp) Count() int {
+	return newIndexer().count(n)
+}
+
+// Nth returns the i-th string of the expansion of n, without expanding the
+// others. It panics if i is out of range.
+func (n *BraceExp) Nth(i int, flags ...ExpandFlags) string {
+	var flag ExpandFlags
+	for _, f := range flags {
+		flag |= f
+	}
+	x := newIndexer()
+	if c := x.count(n); i < 0 || (c >= 0 && i >= c) {
+		panic("syntax: index out of range")
+	}
+	return string(x.appendNth(nil, n, i, flag))
+}
+
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// permutation is a seeded bijection on [0, size): a balanced Feistel
+// network over the smallest even bit width covering size, cycle-walked back
+// into range.
+type permutation struct {
+	size int
+	half uint
+	keys [6]uint64
+}
+
+func newPermutation(size int, seed uint64) *permutation {
+	p := &permutation{size: size}
+	w := uint(bits.Len64(uint64(size - 1)))
+	p.half = (w + 1) / 2
+	if p.half == 0 {
+		p.half = 1
+	}
+	for i := range p.keys {
+		seed = splitmix64(seed)
+		p.keys[i] = seed
+	}
+	return p
+}
+
+func (p *permutation) round(x uint64) uint64 {
+	mask := uint64(1)<<p.half - 1
+	l, r := x>>p.half, x&mask
+	for _, key := range p.keys {
+		l, r = r, l^(splitmix64(r^key)&mask)
+	}
+	return l<<p.half | r
+}
+
+func (p *permutation) at(i int) int {
+	x := uint64(i)
+	for {
+		x = p.round(x)
+		if x < uint64(p.size) {
+			return int(x)
+		}
+	}
+}
+
+// Shuffled returns an iterator over every string of the expansion of n,
+// each visited once, in a pseudo-random order determined by seed. The
+// expansion is never materialized. It panics if Count overflows.
+func (n *BraceExp) Shuffled(seed uint64, flags ...ExpandFlags) iter.Seq[string] {
+	var flag ExpandFlags
+	for _, f := range flags {
+		flag |= f
+	}
+	x := newIndexer()
+	c := x.count(n)
+	if c < 0 {
+		panic("syntax: expansion too large to shuffle")
+	}
+	return func(yield func(string) bool) {
+		p := newPermutation(c, seed)
+		var buf []byte
+		for i := 0; i < c; i++ {
+			buf = x.appendNth(buf[:0], n, p.at(i), flag)
+			if !yield(string(buf)) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package syntax_test
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/pierre-primary/go-braces/syntax"
+)
+
+func TestNth(t *testing.T) {
+	for _, input := range []string{
+		"abc",
+		"{a,b{1..3},c}{x,y}",
+		"{-05..5..3}{z..x}",
+		`{\a,"b"}{,}`,
+	} {
+		exp, err := syntax.Parse(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := exp.Expand(nil)
+		if exp.Count() != len(expected) {
+			t.Fatal(input, exp.Count())
+		}
+		for i, str := range expected {
+			if exp.Nth(i) != str {
+				t.Fatal(input, i, exp.Nth(i))
+			}
+		}
+	}
+
+	exp, _ := syntax.Parse("{0..999999}{0..999999}{0..999999}{0..999999}")
+	if exp.Count() != -1 {
+		t.Fatal(exp.Count())
+	}
+	if str := exp.Nth(1234567); str != "001234567" {
+		t.Fatal(str)
+	}
+}
+
+func TestShuffled(t *testing.T) {
+	exp, err := syntax.Parse("{a..z}{0..99}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var first []string
+	seen := make(map[string]bool)
+	for str := range exp.Shuffled(42) {
+		if seen[str] {
+			t.Fatal("duplicate", str)
+		}
+		seen[str] = true
+		first = append(first, str)
+	}
+	if len(first) != exp.Count() {
+		t.Fatal(len(first))
+	}
+	i := 0
+	for str := range exp.Shuffled(42) {
+		if str != first[i] {
+			t.Fatal("not deterministic")
+		}
+		i++
+	}
+	same := 0
+	i = 0
+	for str := range exp.Shuffled(43) {
+		if str == first[i] {
+			same++
+		}
+		i++
+	}
+	if same == len(first) {
+		t.Fatal("seed ignored")
+	}
+	for str := range exp.Shuffled(0) {
+		_ = str
+		break
+	}
+}
+
+func TestSample(t *testing.T) {
+	exp, err := syntax.Parse("{a,b{0..98}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	// "a" is one of 100 strings, not one of two alternatives
+	hits := 0
+	for _, str := range exp.Sample(rng, 10000, true) {
+		if str == "a" {
+			hits++
+		}
+	}
+	if hits > 300 {
+		t.Fatal(hits)
+	}
+
+	seen := make(map[string]bool)
+	for _, str := range exp.Sample(rng, 50, false) {
+		if seen[str] {
+			t.Fatal("duplicate", str)
+		}
+		seen[str] = true
+	}
+	if len(seen) != 50 {
+		t.Fatal(len(seen))
+	}
+	if data := exp.Sample(rng, 1000, false); len(data) != 100 {
+		t.Fatal(len(data))
+	}
+}
+
+func TestSlice(t *testing.T) {
+	for _, input := range []string{
+		"abc",
+		"{a,b{1..3},c}{x,y}",
+		"h{01..12}.{a..c}-{,z}",
+		`{\a,"b,c"}{1..5..2}`,
+	} {
+		exp, err := syntax.Parse(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := exp.Expand(nil)
+		for i := 0; i <= len(expected); i++ {
+			for j := i + 1; j <= len(expected); j++ {
+				part := exp.Slice(i, j)
+				shipped, err := syntax.Parse(part.String())
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, result := range [][]string{part.Expand(nil), shipped.Expand(nil)} {
+					if len(result) != j-i {
+						t.Fatal(input, i, j, part, result)
+					}
+					for k := range result {
+						if result[k] != expected[i+k] {
+							t.Fatal(input, i, j, part, result)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	exp, _ := syntax.Parse("{0..9999}-{a..z}")
+	if part := exp.Slice(26*100+3, 26*200+5).String(); part != "{100-{d..z},{101..199}-{a..z},200-{a..e}}" {
+		t.Fatal(part)
+	}
+}
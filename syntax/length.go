@@ -0,0 +1,166 @@
+package syntax
+
+import (
+	"math"
+	"unicode/utf8"
+)
+
+func addInt(a, b int) (ok bool, n int) {
+	if a > MaxInt-b {
+		return false, 0
+	}
+	return true, a + b
+}
+
+// countIn returns how many items of the ascending progression lo, lo+step,
+// ..., hi lie in [a, b].
+func countIn(lo, hi, step, a, b int) int {
+	if b < lo || a > hi || a > b {
+		return 0
+	}
+	a, b = max(a, lo), min(b, hi)
+	first := (uint(a-lo) + uint(step) - 1) / uint(step)
+	last := uint(b-lo) / uint(step)
+	if last < first {
+		return 0
+	}
+	return int(last - first + 1)
+}
+
+// intRangeTotal returns the summed length of the items of an integer range,
+// counting the items of each digit width at once.
+func intRangeTotal(sta, num, sep, wid int, f numFormat) (ok bool, total int) {
+	lo, hi, step := sta, sta+num*sep, int(absToUint(sep))
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	if step == 0 {
+		return true, intLen(sta, wid, f)
+	}
+	base, pre := int(f.base()), len(f.prefix())
+	low, high := 0, 0
+	for d := 1; low <= hi || -low >= lo; d++ {
+		// high is the largest number of d digits
+		if high > (MaxInt-(base-1))/base {
+			high = MaxInt
+		} else {
+			high = high*base + base - 1
+		}
+		neg := -high
+		if high == MaxInt {
+			neg = math.MinInt
+		}
+		var n int
+		n = countIn(lo, hi, step, low, high)
+		if n > 0 {
+			if ok, n = mulInt(n, max(d+pre, wid)); !ok {
+				return false, 0
+			}
+			if ok, total = addInt(total, n); !ok {
+				return false, 0
+			}
+		}
+		n = countIn(lo, hi, step, neg, min(-low, -1))
+		if n > 0 {
+			if ok, n = mulInt(n, max(d+1+pre, wid)); !ok {
+				return false, 0
+			}
+			if ok, total = addInt(total, n); !ok {
+				return false, 0
+			}
+		}
+		if high == MaxInt {
+			break
+		}
+		low = high + 1
+	}
+	return true, total
+}
+
+var runeBands = [4][2]int{{0, 0x7f}, {0x80, 0x7ff}, {0x800, 0xffff}, {0x10000, utf8.MaxRune}}
+
+// charRangeTotal returns the summed UTF-8 length of the items of a char
+// range.
+func charRangeTotal(sta, num, sep int) (ok bool, total int) {
+	lo, hi, step := sta, sta+num*sep, int(absToUint(sep))
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	if step == 0 {
+		return true, utf8.RuneLen(rune(sta))
+	}
+	for i, band := range runeBands {
+		total += countIn(lo, hi, step, band[0], band[1]) * (i + 1)
+	}
+	return true, total
+}
+
+// total returns the summed length of all strings produced by exp.
+func (x *indexer) total(exp *BraceExp, flags ExpandFlags) (ok bool, total int) {
+	switch exp.Op {
+	case OpConcat:
+		// total(ab) = total(a)*count(b) + count(a)*total(b)
+		count := 1
+		for _, sub := range exp.Subs {
+			c := x.count(sub)
+			if c < 0 {
+				return false, 0
+			}
+			ok, t := x.total(sub, flags)
+			if !ok {
+				return false, 0
+			}
+			var l, r int
+			if ok, l = mulInt(total, c); !ok {
+				return false, 0
+			}
+			if ok, r = mulInt(count, t); !ok {
+				return false, 0
+			}
+			if ok, total = addInt(l, r); !ok {
+				return false, 0
+			}
+			if ok, count = mulInt(count, c); !ok {
+				return false, 0
+			}
+		}
+		return true, total
+	case OpAlternate:
+		for _, sub := range exp.Subs {
+			ok, t := x.total(sub, flags)
+			if !ok {
+				return false, 0
+			}
+			if ok, total = addInt(total, t); !ok {
+				return false, 0
+			}
+		}
+		return true, total
+	case OpIntegerRange:
+		sta, num, sep, wid := rangeData(exp)
+		return intRangeTotal(sta, num, sep, wid, rangeFormat(exp))
+	case OpCharRange:
+		sta, num, sep, _ := rangeData(exp)
+		return charRangeTotal(sta, num, sep)
+	default:
+		return true, len(appendConst(nil, exp, flags))
+	}
+}
+
+// Lengths returns the length of the shortest and of the longest string of
+// the expansion of n, and the summed length of all of them, or -1 for total
+// if it overflows an int. It is computed from the tree: literal lengths,
+// digit widths and padding of integer ranges, and UTF-8 widths of char
+// ranges.
+func (n *BraceExp) Lengths(flags ...ExpandFlags) (min, max, total int) {
+	var flag ExpandFlags
+	for _, f := range flags {
+		flag |= f
+	}
+	min, max = lenBounds(n, flag)
+	x := newIndexer()
+	if ok, t := x.total(n, flag); ok && x.count(n) >= 0 {
+		return min, max, t
+	}
+	return min, max, -1
+}
@@ -0,0 +1,134 @@
+package syntax
+
+import "unicode/utf8"
+
+// tail returns a suffix shared by all strings produced by exp. complete is
+// set when exp only produces that string.
+func tail(exp *BraceExp, flags ExpandFlags) (suffix []byte, complete bool) {
+	switch exp.Op {
+	case OpConcat:
+		complete = true
+		for i := len(exp.Subs) - 1; i >= 0 && complete; i-- {
+			var t []byte
+			t, complete = tail(exp.Subs[i], flags)
+			suffix = append(t, suffix...)
+		}
+		return suffix, complete
+	case OpAlternate:
+		suffix, complete = tail(exp.Subs[0], flags)
+		for _, sub := range exp.Subs[1:] {
+			t, c := tail(sub, flags)
+			n := 0
+			for n < len(t) && n < len(suffix) && t[len(t)-1-n] == suffix[len(suffix)-1-n] {
+				n++
+			}
+			// never start inside a UTF-8 sequence
+			for n > 0 && !utf8.RuneStart(suffix[len(suffix)-n]) {
+				n--
+			}
+			complete = complete && c && n == len(t) && n == len(suffix)
+			suffix = suffix[len(suffix)-n:]
+		}
+		return suffix, complete
+	case OpIntegerRange:
+		sta, num, sep, wid := rangeData(exp)
+		f := rangeFormat(exp)
+		if num == 0 {
+			return appendInt(nil, sta, wid, f), true
+		}
+		end := sta + num*sep
+		if (sta < 0) != (end < 0) {
+			return nil, false
+		}
+		// all items agree on as many trailing digits as sep has trailing
+		// zeros, within the digits of the shortest item
+		k, base := 0, f.base()
+		for u := absToUint(sep); u%base == 0; u /= base {
+			k++
+		}
+		short := sta
+		if absToUint(end) < absToUint(sta) {
+			short = end
+		}
+		digits := intLen(short, wid, f) - len(f.prefix())
+		if short < 0 {
+			digits--
+		}
+		k = min(k, digits)
+		val := appendInt(nil, sta, wid, f)
+		return val[len(val)-k:], false
+	case OpCharRange:
+		sta, num, _, _ := rangeData(exp)
+		if num == 0 {
+			return appendRangeValue(nil, exp.Op, sta, 0, 0), true
+		}
+		return nil, false
+	default:
+		return appendConst(nil, exp, flags), true
+	}
+}
+
+// CommonPrefix returns the literal text every string of the expansion of n
+// starts with.
+func (n *BraceExp) CommonPrefix(flags ...ExpandFlags) string {
+	var flag ExpandFlags
+	for _, f := range flags {
+		flag |= f
+	}
+	prefix, _ := head(n, flag)
+	return string(prefix)
+}
+
+// CommonSuffix returns the literal text every string of the expansion of n
+// ends with.
+func (n *BraceExp) CommonSuffix(flags ...ExpandFlags) string {
+	var flag ExpandFlags
+	for _, f := range flags {
+		flag |= f
+	}
+	suffix, _ := tail(n, flag)
+	return string(suffix)
+}
+
+// RequiredSubstrings returns literal strings that every string of the
+// expansion of n contains, in the order they appear in the pattern. Each
+// one is a run of constant parts, extended with the common suffix and
+// prefix of the groups around it.
+func (n *BraceExp) RequiredSubstrings(flags ...ExpandFlags) []string {
+	var flag ExpandFlags
+	for _, f := range flags {
+		flag |= f
+	}
+	subs := []*BraceExp{n}
+	if n.Op == OpConcat {
+		subs = n.Subs
+	}
+
+	var data []string
+	var cur []byte
+	flush := func() {
+		if len(cur) == 0 {
+			return
+		}
+		for _, str := range data {
+			if str == string(cur) {
+				cur = cur[:0]
+				return
+			}
+		}
+		data = append(data, string(cur))
+		cur = cur[:0]
+	}
+	for _, sub := range subs {
+		prefix, complete := head(sub, flag)
+		cur = append(cur, prefix...)
+		if complete {
+			continue
+		}
+		flush()
+		suffix, _ := tail(sub, flag)
+		cur = append(cur, suffix...)
+	}
+	flush()
+	return data
+}
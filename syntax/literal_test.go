@@ -0,0 +1,55 @@
+package syntax_test
+
+import (
+	"testing"
+
+	"github.com/pierre-primary/go-braces/syntax"
+)
+
+func TestLiterals(t *testing.T) {
+	literals := func(input, prefix, suffix string, required []string, flags ...syntax.ParseFlags) {
+		exp, err := syntax.Parse(input, flags...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p := exp.CommonPrefix(); p != prefix {
+			t.Fatal(input, "prefix", p)
+		}
+		if s := exp.CommonSuffix(); s != suffix {
+			t.Fatal(input, "suffix", s)
+		}
+		r := exp.RequiredSubstrings()
+		if len(r) != len(required) {
+			t.Fatal(input, r)
+		}
+		for i := range r {
+			if r[i] != required[i] {
+				t.Fatal(input, r)
+			}
+		}
+		exp.Walk(func(str string) {
+			if len(str) < len(prefix) || str[:len(prefix)] != prefix {
+				t.Fatal(input, str, "misses prefix")
+			}
+			if len(str) < len(suffix) || str[len(str)-len(suffix):] != suffix {
+				t.Fatal(input, str, "misses suffix")
+			}
+		})
+	}
+
+	literals("logs/{app,db}/2024-{01..12}.gz", "logs/", ".gz", E{"logs/", "/2024-", ".gz"})
+	literals("abc", "abc", "abc", E{"abc"})
+	literals("{ab,ac}x{yz,z}", "a", "z", E{"a", "x", "z"})
+	literals("{100..199}", "1", "", E{"1"})
+	literals("{-100..-199}", "-1", "", E{"-1"})
+	literals("{-10..10}", "", "", nil)
+	literals("{10..90..20}k", "", "0k", E{"0k"})
+	literals("{0..200..100}", "", "0", E{"0"})
+	literals("{005..205..100}", "", "05", E{"05"})
+	literals("{a..c}{x..z}", "", "", nil)
+	literals(`"q"\{{a,b}`, "q{", "", E{"q{"})
+	// bytes shared by different runes are not part of a prefix or suffix
+	literals("{中..丰}", "", "", nil, syntax.AnyCharRange)
+	literals("{中,丰}x", "", "x", E{"x"})
+	literals("{ā,Ł}", "", "", nil)
+}
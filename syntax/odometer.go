@@ -0,0 +1,196 @@
+package syntax
+
+// state is the position of an odometer inside one node. Unlike walk, which
+// follows the Next links, states only look at Subs, so the position of every
+// group is kept apart and groups can be advanced in any order.
+type state struct {
+	exp  *BraceExp
+	idx  int      // range offset or index of the current alternate item
+	subs []*state // states of the concat parts, or of the current item
+}
+
+func newState(exp *BraceExp) *state {
+	s := &state{exp: exp}
+	switch exp.Op {
+	case OpConcat:
+		s.subs = make([]*state, len(exp.Subs))
+		for i, sub := range exp.Subs {
+			s.subs[i] = newState(sub)
+		}
+	case OpAlternate:
+		s.subs = []*state{newState(exp.Subs[0])}
+	}
+	return s
+}
+
+func (s *state) append(buf []byte, flags ExpandFlags) []byte {
+	switch s.exp.Op {
+	case OpConcat:
+		for _, sub := range s.subs {
+			buf = sub.append(buf, flags)
+		}
+		return buf
+	case OpAlternate:
+		return s.subs[0].append(buf, flags)
+	case OpIntegerRange, OpCharRange:
+		sta, _, sep, wid := rangeData(s.exp)
+		return appendRangeValue(buf, s.exp.Op, sta+s.idx*sep, wid, rangeFormat(s.exp))
+	default:
+		return appendConst(buf, s.exp, flags)
+	}
+}
+
+// odometer enumerates an expression by advancing its groups like the wheels
+// of an odometer. order lists the parts of the root concat from the fastest
+// to the slowest wheel; nested concats turn their rightmost part fastest, or
+// their leftmost one when leftmost is set.
+type odometer struct {
+	root     *state
+	order    []int
+	leftmost bool
+}
+
+func (o *odometer) advanceConcat(s *state, order []int) bool {
+	n := len(s.subs)
+	for i := 0; i < n; i++ {
+		var sub *state
+		switch {
+		case order != nil:
+			sub = s.subs[order[i]]
+		case o.leftmost:
+			sub = s.subs[i]
+		default:
+			sub = s.subs[n-1-i]
+		}
+		if !o.advance(sub, nil) {
+			return false
+		}
+	}
+	return true
+}
+
+// advance moves s to its next position. It reports a carry when s wrapped
+// around to its first position.
+func (o *odometer) advance(s *state, order []int) bool {
+	switch s.exp.Op {
+	case OpConcat:
+		return o.advanceConcat(s, order)
+	case OpAlternate:
+		if !o.advance(s.subs[0], nil) {
+			return false
+		}
+		if s.idx++; s.idx < len(s.exp.Subs) {
+			s.subs[0] = newState(s.exp.Subs[s.idx])
+			return false
+		}
+		s.idx = 0
+		s.subs[0] = newState(s.exp.Subs[0])
+		return true
+	case OpIntegerRange, OpCharRange:
+		_, num, _, _ := rangeData(s.exp)
+		if s.idx++; s.idx <= num {
+			return false
+		}
+		s.idx = 0
+		return true
+	default:
+		return true
+	}
+}
+
+func (o *odometer) next() bool {
+	return !o.advance(o.root, o.order)
+}
+
+// groupOrder turns a priority list of top-level groups into the order in
+// which the parts of the root concat are advanced. Groups are the parts that
+// are not constant, numbered from left to right. Groups missing from
+// priority turn slower than the listed ones.
+func groupOrder(exp *BraceExp, priority []int, leftmost bool) []int {
+	if exp.Op != OpConcat {
+		return nil
+	}
+	var groups []int
+	for i, sub := range exp.Subs {
+		if !isConst(sub) {
+			groups = append(groups, i)
+		}
+	}
+	order := make([]int, 0, len(exp.Subs))
+	used := make([]bool, len(exp.Subs))
+	for _, g := range priority {
+		if g >= 0 && g < len(groups) && !used[groups[g]] {
+			used[groups[g]] = true
+			order = append(order, groups[g])
+		}
+	}
+	for i := range exp.Subs {
+		if !leftmost {
+			i = len(exp.Subs) - 1 - i
+		}
+		if !used[i] {
+			order = append(order, i)
+		}
+	}
+	return order
+}
+
+func walkOrdered(exp *BraceExp, flags ExpandFlags, priority []int, handler WalkBytesHandler, buffer []byte) []byte {
+	o := odometer{
+		root:     newState(exp),
+		order:    groupOrder(exp, priority, flags&LeftmostFastest != 0),
+		leftmost: flags&LeftmostFastest != 0,
+	}
+	offset := len(buffer)
+	for {
+		buffer = o.root.append(buffer[:offset], flags)
+		handler(buffer)
+		if !o.next() {
+			return buffer
+		}
+	}
+}
+
+// WalkPriority walks the expansion of n, turning the top-level brace groups
+// in the given priority: priority lists group indexes, counted from zero
+// from the left, from the fastest changing group to the slowest one.
+func (n *BraceExp) WalkPriority(handler WalkHandler, priority []int, flags ...ExpandFlags) {
+	var flag ExpandFlags
+	for _, f := range flags {
+		flag |= f
+	}
+	h := stringHandler(handler)
+	if flag&Unique != 0 && !isUnique(n, flag) {
+		h = uniqueHandler(h)
+	}
+	walkOrdered(n, flag, priority, h, nil)
+}
+
+// seek moves s to the i-th string of its expression, numbered in walk order.
+func (x *indexer) seek(s *state, i int) {
+	switch s.exp.Op {
+	case OpConcat:
+		for k := len(s.subs) - 1; k >= 0; k-- {
+			if c := x.count(s.exp.Subs[k]); c > 0 {
+				x.seek(s.subs[k], i%c)
+				i /= c
+			} else {
+				x.seek(s.subs[k], i)
+				i = 0
+			}
+		}
+	case OpAlternate:
+		for k, sub := range s.exp.Subs {
+			c := x.count(sub)
+			if c < 0 || i < c || k == len(s.exp.Subs)-1 {
+				s.idx = k
+				s.subs[0] = newState(sub)
+				x.seek(s.subs[0], i)
+				return
+			}
+			i -= c
+		}
+	case OpIntegerRange, OpCharRange:
+		s.idx = i
+	}
+}
@@ -0,0 +1,69 @@
+package syntax
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// IndexHandler receives a string together with its index in walk order.
+type IndexHandler func(idx int, str string)
+
+// ExpandParallel expands n on several goroutines. The index space is cut
+// into chunks that workers take in turn; each worker positions its own
+// odometer at the start of a chunk and owns its buffer. handler is called
+// concurrently and receives the index of every string, so callers can
+// restore the walk order if they need it. workers <= 0 means GOMAXPROCS.
+// Only KeepEscape and KeepQuote apply. The walk stops early with ctx.Err()
+// when ctx is done.
+func (n *BraceExp) ExpandParallel(ctx context.Context, workers int, handler IndexHandler, flags ...ExpandFlags) error {
+	var flag ExpandFlags
+	for _, f := range flags {
+		flag |= f
+	}
+	x := newIndexer()
+	total := x.count(n)
+	if total < 0 {
+		return ErrOverflow
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	chunk := total / (workers * 8)
+	if chunk < 1 {
+		chunk = 1
+	} else if chunk > 1<<16 {
+		chunk = 1 << 16
+	}
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var buf []byte
+			for ctx.Err() == nil {
+				lo := int(next.Add(int64(chunk))) - chunk
+				if lo >= total {
+					return
+				}
+				hi := min(lo+chunk, total)
+
+				o := odometer{root: newState(n)}
+				x.seek(o.root, lo)
+				for i := lo; i < hi; i++ {
+					if (i-lo)%checkInterval == checkInterval-1 && ctx.Err() != nil {
+						return
+					}
+					buf = o.root.append(buf[:0], flag)
+					handler(i, string(buf))
+					o.next()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
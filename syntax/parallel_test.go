@@ -0,0 +1,41 @@
+package syntax_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/pierre-primary/go-braces/syntax"
+)
+
+func TestExpandParallel(t *testing.T) {
+	exp, err := syntax.Parse("{a,b{1..30},c}-{00..99}{x..z}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := exp.Expand(nil)
+	result := make([]string, len(expected))
+	var mu sync.Mutex
+	err = exp.ExpandParallel(context.Background(), 4, func(idx int, str string) {
+		mu.Lock()
+		defer mu.Unlock()
+		if result[idx] != "" {
+			t.Error("duplicate index", idx)
+		}
+		result[idx] = str
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range expected {
+		if result[i] != expected[i] {
+			t.Fatal(i, result[i], expected[i])
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := exp.ExpandParallel(ctx, 4, func(int, string) {}); err != context.Canceled {
+		t.Fatal(err)
+	}
+}
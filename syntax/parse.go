@@ -3,7 +3,6 @@ package syntax
 import (
 	"math"
 	"unicode/utf8"
-	"unsafe"
 )
 
 const (
@@ -25,12 +24,36 @@ const (
 	IgnoreQuote
 	AnyCharRange
 	StrictMode
+	// BashCompat parses like GNU bash 5: braces, sequences, padding and
+	// quoting follow bash brace expansion exactly.
+	BashCompat
+	// ZshDialect parses like zsh with BRACE_CCL set: braces that are neither
+	// a list nor a range list characters, a negative step reverses a range,
+	// and a zero-padded bound pads to the widest bound.
+	ZshDialect
+	// JSCompat parses like the braces package of Node.js: padding follows
+	// fill-range, any two characters make a range, backquotes quote, and
+	// brackets and ${...} are left alone.
+	JSCompat
+	// CurlDialect parses URL globs like curl: {a,b} sets that do not nest,
+	// [01-10:2] and [a-z] ranges, and a backslash escaping glob characters.
+	CurlDialect
+	// AnsibleDialect parses host patterns like Ansible inventories:
+	// www[01:50:2] and db-[a:f]-node, with inclusive ends and a stride.
+	AnsibleDialect
+	// HexRange reads bare bounds of integer ranges as hexadecimal digits, as
+	// in {00..ff}. Bounds with a 0x or 0o prefix are read in their base and
+	// keep their prefix with or without it. The step of a hexadecimal or
+	// octal range is written in the base of its bounds.
+	HexRange
 )
 
 type Parser struct {
-	flags ParseFlags
-	stack []*BraceExp
-	free  *BraceExp
+	flags  ParseFlags
+	tokens *tokens
+	err    error // an invalid syntax, returned by every parse
+	stack  []*BraceExp
+	free   *BraceExp
 }
 
 func NewParser(flags ...ParseFlags) *Parser {
@@ -41,10 +64,26 @@ func NewParser(flags ...ParseFlags) *Parser {
 	return &Parser{flags: flag}
 }
 
+// NewParserWithSyntax returns a parser that reads brace expressions written
+// with the tokens of syntax. The bash, curl and Ansible dialects have tokens
+// of their own and ignore it. When syntax is not valid, every parse fails
+// with the error of Validate.
+func NewParserWithSyntax(syntax Syntax, flags ...ParseFlags) *Parser {
+	p := NewParser(flags...)
+	if p.err = syntax.Validate(); p.err == nil {
+		p.tokens = newTokens(syntax)
+	}
+	return p
+}
+
 func Parse(input string, flags ...ParseFlags) (*BraceExp, error) {
 	return NewParser(flags...).Parse(input)
 }
 
+func ParseWithSyntax(input string, syntax Syntax, flags ...ParseFlags) (*BraceExp, error) {
+	return NewParserWithSyntax(syntax, flags...).Parse(input)
+}
+
 func ParseWithBuffer(input string, buffer []byte, flags ...ParseFlags) (*BraceExp, []byte, error) {
 	return NewParser(flags...).ParseWithBuffer(input, buffer)
 }
@@ -177,7 +216,7 @@ func (p *Parser) literalize(offset int, backward bool, buffer []byte) []byte {
 	return buffer
 }
 
-func careateRangeData(sta, end, sep, wid int) (bool, []byte) {
+func careateRangeData(sta, end, sep, wid int, f numFormat) (bool, []byte) {
 	var num int
 	if sta == end {
 		sep = 0
@@ -211,13 +250,11 @@ func careateRangeData(sta, end, sep, wid int) (bool, []byte) {
 		}
 	}
 
-	opts := [4]int{sta, int(num), sep, wid}
-	return true, unsafe.Slice((*byte)(unsafe.Pointer(&opts[0])), int(unsafe.Sizeof(opts)))
+	return true, packRangeData(sta, num, sep, wid, f)
 }
 
 func (p *Parser) ranges(offset int) (ok bool) {
 	set := p.stack[offset:]
-	sep := 0
 	switch len(set) {
 	default:
 		return false
@@ -225,9 +262,6 @@ func (p *Parser) ranges(offset int) (ok bool) {
 		if _ = set[5]; set[4].Op != opBraceRange || set[5].Op != OpLiteral {
 			return false
 		}
-		if ok, sep = parseInt(set[5].Val); !ok {
-			return false
-		}
 		fallthrough
 	case 4:
 		if _ = set[3]; set[2].Op != opBraceRange {
@@ -256,11 +290,15 @@ func (p *Parser) ranges(offset int) (ok bool) {
 
 	op, wid := OpUnknown, 0
 	var sta, end int
-	if ls == 1 && le == 1 {
+	var f numFormat
+	if p.flags&(ZshDialect|JSCompat) == 0 {
+		op, sta, end, wid, f = p.radixRange(vs, ve)
+	}
+	if op == OpUnknown && ls == 1 && le == 1 {
 		cs, ce := vs[0], ve[0]
 		if isDigit(cs) && isDigit(ce) {
 			op, sta, end = OpIntegerRange, int(cs-'0'), int(ce-'0')
-		} else if p.flags&AnyCharRange != 0 && cs < utf8.RuneSelf && ce < utf8.RuneSelf {
+		} else if p.flags&(AnyCharRange|ZshDialect|JSCompat) != 0 && cs < utf8.RuneSelf && ce < utf8.RuneSelf {
 			op, sta, end = OpCharRange, int(cs), int(ce)
 		} else if (isUpperCase(cs) && isUpperCase(ce)) || (isLowerCase(cs) && isLowerCase(ce)) {
 			op, sta, end = OpCharRange, int(cs), int(ce)
@@ -284,10 +322,24 @@ func (p *Parser) ranges(offset int) (ok bool) {
 		if wid < le && le >= 2 && (ve[0] == '0' || (ve[0] == '-' && ve[1] == '0')) {
 			wid = le
 		}
+		if wid > 0 && p.flags&ZshDialect != 0 {
+			wid = max(ls, le)
+		}
+		if p.flags&JSCompat != 0 {
+			// fill-range pads to the longest of the bounds and the step
+			wid = 0
+			var vp []byte
+			if len(set) == 6 {
+				vp = set[5].Val
+			}
+			if isZeroPadded(vs) || isZeroPadded(ve) || isZeroPadded(vp) {
+				wid = max(ls, le, len(vp))
+			}
+		}
 	}
 
 	switch {
-	case p.flags&AnyCharRange != 0 && op == OpUnknown:
+	case p.flags&(AnyCharRange|ZshDialect|JSCompat) != 0 && op == OpUnknown:
 		rs, rw := utf8.DecodeRune(vs)
 		if rs == utf8.RuneError || rw != ls {
 			break
@@ -303,7 +355,14 @@ func (p *Parser) ranges(offset int) (ok bool) {
 		return false
 	}
 
-	ok, data := careateRangeData(sta, end, sep, wid)
+	sep := 0
+	if len(set) == 6 {
+		if ok, sep = parseStep(set[5].Val, f); !ok {
+			return false
+		}
+	}
+
+	ok, data := careateRangeData(sta, end, sep, wid, f)
 	if !ok {
 		return false
 	}
@@ -315,11 +374,54 @@ func (p *Parser) ranges(offset int) (ok bool) {
 
 	exp := p.newExp(op)
 	exp.Val = data
+	if sep < 0 && p.flags&ZshDialect != 0 {
+		// zsh counts from the first bound, then outputs the items reversed
+		sta, num, sep, wid := rangeData(exp)
+		exp.Val = packRangeData(sta+num*sep, num, -sep, wid, 0)
+	}
 	p.push(exp)
 	return true
 }
 
+// radixRange parses the bounds of a hexadecimal or octal range. Both bounds
+// must share the base and the prefix, and hex digits of a single case. Like
+// decimal bounds, a zero-padded bound pads every number to its width.
+func (p *Parser) radixRange(vs, ve []byte) (op Op, sta, end, wid int, f numFormat) {
+	hex := p.flags&HexRange != 0
+	ok, sta, fs, ps, ls := parseRadixInt(vs, hex)
+	if !ok {
+		return OpUnknown, 0, 0, 0, 0
+	}
+	ok, end, fe, pe, le := parseRadixInt(ve, hex)
+	if !ok || fs&^fmtUpper != fe&^fmtUpper || (fs|fe)&fmtUpper != 0 && (ls || le) {
+		return OpUnknown, 0, 0, 0, 0
+	}
+	if ps {
+		wid = len(vs)
+	}
+	if pe && len(ve) > wid {
+		wid = len(ve)
+	}
+	return OpIntegerRange, sta, end, wid, fs | fe
+}
+
 func (p *Parser) ParseWithBuffer(input string, buffer []byte) (*BraceExp, []byte, error) {
+	if p.err != nil {
+		return nil, buffer, p.err
+	}
+	if p.flags&BashCompat != 0 {
+		exp, err := parseBash(input, p.flags)
+		return exp, buffer, err
+	}
+	if p.flags&CurlDialect != 0 {
+		exp, err := parseCurl(input)
+		return exp, buffer, err
+	}
+	if p.flags&AnsibleDialect != 0 {
+		exp, err := parseAnsible(input)
+		return exp, buffer, err
+	}
+
 	type block struct {
 		base   int // Base Stack Index
 		ranges int
@@ -344,6 +446,7 @@ func (p *Parser) ParseWithBuffer(input string, buffer []byte) (*BraceExp, []byte
 
 	var que, esc byte
 	var queSta int
+	var dollar int // depth of ${...} braces, which are never expanded
 
 	for end := 0; ; end++ {
 		goto Skip
@@ -376,7 +479,19 @@ func (p *Parser) ParseWithBuffer(input string, buffer []byte) (*BraceExp, []byte
 
 		/** In Quoted **/
 		if que > 0 {
-			if que != ch || input[end-1] == '\\' {
+			if p.flags&JSCompat != 0 {
+				// a backslash keeps the next character, quote included
+				if ch == '\\' && end+1 < len(input) {
+					if sta < 0 {
+						sta = end
+					}
+					end++
+					goto Regular
+				}
+				if que != ch {
+					goto Regular
+				}
+			} else if que != ch || input[end-1] == '\\' {
 				goto Regular
 			}
 
@@ -387,10 +502,17 @@ func (p *Parser) ParseWithBuffer(input string, buffer []byte) (*BraceExp, []byte
 			continue
 		}
 
-		switch ch {
+		var kind tokenKind
+		var tok string
+		if p.tokens == nil {
+			kind, tok = defaultToken(input[end:])
+		} else {
+			kind, tok = p.tokens.token(input[end:])
+		}
+		switch {
 		default:
 			goto Regular
-		case '\\':
+		case ch == '\\':
 			/** Escape Character **/
 			if p.flags&IgnoreEscape != 0 {
 				goto Regular
@@ -409,9 +531,9 @@ func (p *Parser) ParseWithBuffer(input string, buffer []byte) (*BraceExp, []byte
 			}
 
 			p.op(OpEscape, "\\")
-		case '"', '\'':
+		case ch == '"' || ch == '\'' || ch == '`':
 			/** Quoted Character **/
-			if p.flags&IgnoreQuote != 0 {
+			if p.flags&IgnoreQuote != 0 || (ch == '`' && p.flags&JSCompat == 0) {
 				goto Regular
 			}
 			submit(end)
@@ -419,16 +541,21 @@ func (p *Parser) ParseWithBuffer(input string, buffer []byte) (*BraceExp, []byte
 			que = ch
 			queSta = end
 			p.op(OpQuote, string(ch))
-		case '{':
+		case kind == tokOpen:
 			/** Braces Open **/
+			if p.flags&JSCompat != 0 && (dollar > 0 || (end > 0 && input[end-1] == '$')) {
+				dollar++
+				goto Regular
+			}
 			submit(end)
 
 			blocks = append(blocks, block{base: len(p.stack), delims: 0, ranges: 0})
 			blk = &blocks[len(blocks)-1]
-			p.op(opBraceOpen, "{")
-		case ',':
+			end += len(tok) - 1
+			p.op(opBraceOpen, tok)
+		case kind == tokSeparator:
 			/** Braces Comma Separator **/
-			if blk == nil {
+			if blk == nil || dollar > 0 {
 				goto Regular
 			}
 			submit(end)
@@ -440,13 +567,15 @@ func (p *Parser) ParseWithBuffer(input string, buffer []byte) (*BraceExp, []byte
 
 			blk.delims++
 			p.concat(-1)
-			p.op(opBraceDelim, ",")
-		case '.':
+			end += len(tok) - 1
+			p.op(opBraceDelim, tok)
+		case kind == tokRange:
 			/** Braces Range Separator **/
-			if blk == nil || blk.delims > 0 || blk.ranges < 0 || blk.ranges >= 2 {
+			if blk == nil || dollar > 0 || blk.delims > 0 || blk.ranges < 0 || blk.ranges >= 2 {
 				goto Regular
 			}
-			if end+1 >= len(input) || input[end+1] != '.' {
+			if top := p.stack[len(p.stack)-1]; p.tokens != nil && sta < 0 && (top.Op == opBraceOpen || top.Op == opBraceRange) {
+				// a bound starts with the token, as in [-2--1] with "-"
 				goto Regular
 			}
 			submit(end)
@@ -455,12 +584,16 @@ func (p *Parser) ParseWithBuffer(input string, buffer []byte) (*BraceExp, []byte
 				blk.ranges = ^blk.ranges
 				goto Regular
 			}
-			end++
+			end += len(tok) - 1
 
 			blk.ranges++
-			p.op(opBraceRange, "..")
-		case '}':
+			p.op(opBraceRange, tok)
+		case kind == tokClose:
 			/** Braces Close **/
+			if dollar > 0 {
+				dollar--
+				goto Regular
+			}
 			if blk == nil {
 				goto Regular
 			}
@@ -472,6 +605,7 @@ func (p *Parser) ParseWithBuffer(input string, buffer []byte) (*BraceExp, []byte
 			}
 
 			submit(end)
+			end += len(tok) - 1
 
 			// Parse Alternate
 			if b.delims > 0 {
@@ -485,9 +619,23 @@ func (p *Parser) ParseWithBuffer(input string, buffer []byte) (*BraceExp, []byte
 				continue
 			}
 
-			// Rollback to literal
+			// Parse character classes
+			if p.flags&ZshDialect != 0 && p.charClass(b.base) {
+				continue
+			}
+
+			// Rollback to literal, the token starting the next literal
 			literalize(b.base, true)
-			goto Regular
+			sta = end + 1 - len(tok)
+		case ch == '[':
+			/** Bracket Expression **/
+			if p.flags&JSCompat == 0 {
+				goto Regular
+			}
+			if sta < 0 {
+				sta = end
+			}
+			end = bracketEnd(input, end)
 		}
 	}
 
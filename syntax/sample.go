@@ -0,0 +1,64 @@
+package syntax
+
+import (
+	"math/rand/v2"
+)
+
+// Sample draws k strings uniformly at random from the expansion of n
+// without enumerating it: every draw picks an index in [0, Count) and
+// descends the tree, so alternatives are weighted by the size of their
+// subtrees. Without replacement no index is drawn twice, and when k
+// reaches Count the whole expansion is returned in random order.
+// Strings that the pattern produces more than once are proportionally
+// more likely to be drawn. It panics if Count overflows.
+func (n *BraceExp) Sample(rng *rand.Rand, k int, replace bool, flags ...ExpandFlags) []string {
+	var flag ExpandFlags
+	for _, f := range flags {
+		flag |= f
+	}
+	x := newIndexer()
+	c := x.count(n)
+	if c < 0 {
+		panic("syntax: expansion too large to sample")
+	}
+	if k <= 0 {
+		return nil
+	}
+
+	var idx []int
+	switch {
+	case replace:
+		idx = make([]int, k)
+		for i := range idx {
+			idx[i] = rng.IntN(c)
+		}
+	case k >= c:
+		idx = make([]int, c)
+		for i := range idx {
+			idx[i] = i
+		}
+		rng.Shuffle(len(idx), func(i, j int) { idx[i], idx[j] = idx[j], idx[i] })
+	default:
+		// Floyd's algorithm, then shuffled as the picks are not in random
+		// order
+		picked := make(map[int]struct{}, k)
+		idx = make([]int, 0, k)
+		for j := c - k; j < c; j++ {
+			t := rng.IntN(j + 1)
+			if _, ok := picked[t]; ok {
+				t = j
+			}
+			picked[t] = struct{}{}
+			idx = append(idx, t)
+		}
+		rng.Shuffle(len(idx), func(i, j int) { idx[i], idx[j] = idx[j], idx[i] })
+	}
+
+	data := make([]string, len(idx))
+	var buf []byte
+	for i, j := range idx {
+		buf = x.appendNth(buf[:0], n, j, flag)
+		data[i] = string(buf)
+	}
+	return data
+}
@@ -0,0 +1,98 @@
+package syntax
+
+func (x *indexer) slice(exp *BraceExp, lo, hi int) *BraceExp {
+	if c := x.count(exp); lo == 0 && hi == c {
+		return clone(exp)
+	}
+	switch exp.Op {
+	case OpConcat:
+		return x.sliceSeq(exp.Subs, lo, hi)
+	case OpAlternate:
+		var items []*BraceExp
+		off := 0
+		for _, sub := range exp.Subs {
+			c := x.count(sub)
+			if c < 0 {
+				c = MaxInt - off
+			}
+			if off < hi && lo < off+c {
+				items = append(items, x.slice(sub, max(lo-off, 0), min(hi-off, c)))
+			}
+			if off += c; off >= hi {
+				break
+			}
+		}
+		return newAlternate(items...)
+	case OpIntegerRange, OpCharRange:
+		sta, _, sep, wid := rangeData(exp)
+		if hi-lo == 1 {
+			return newLiteral(appendRangeValue(nil, exp.Op, sta+lo*sep, wid, rangeFormat(exp)))
+		}
+		return newRange(exp.Op, sta+lo*sep, hi-lo-1, sep, wid, rangeFormat(exp))
+	default:
+		return clone(exp)
+	}
+}
+
+// sliceSeq slices the concatenation of subs. The items sharing the same
+// string of the first part are kept together: at most a partial run at each
+// end, and the full runs in between.
+func (x *indexer) sliceSeq(subs []*BraceExp, lo, hi int) *BraceExp {
+	if len(subs) == 1 {
+		return x.slice(subs[0], lo, hi)
+	}
+	first, rest := subs[0], subs[1:]
+	r := 1
+	for _, sub := range rest {
+		var ok bool
+		if c := x.count(sub); c < 0 {
+			r = MaxInt
+			break
+		} else if ok, r = mulInt(r, c); !ok {
+			r = MaxInt
+			break
+		}
+	}
+
+	q0, q1 := lo/r, (hi-1)/r
+	if q0 == q1 {
+		return newConcat(x.slice(first, q0, q0+1), x.sliceSeq(rest, lo-q0*r, hi-q0*r))
+	}
+
+	var items []*BraceExp
+	if lo%r != 0 {
+		items = append(items, newConcat(x.slice(first, q0, q0+1), x.sliceSeq(rest, lo%r, r)))
+		q0++
+	}
+	end := q1 + 1
+	if hi%r != 0 {
+		end = q1
+	}
+	if q0 < end {
+		whole := []*BraceExp{x.slice(first, q0, end)}
+		for _, sub := range rest {
+			whole = append(whole, clone(sub))
+		}
+		items = append(items, newConcat(whole...))
+	}
+	if hi%r != 0 {
+		items = append(items, newConcat(x.slice(first, q1, q1+1), x.sliceSeq(rest, 0, hi%r)))
+	}
+	return newAlternate(items...)
+}
+
+// Slice returns a new expression that expands to the strings i through
+// j-1 of the expansion of n, built by narrowing ranges and splitting
+// alternates, so it stays compact and can be shipped with String. It
+// returns nil if i == j and panics if the bounds are out of range.
+func (n *BraceExp) Slice(i, j int) *BraceExp {
+	x := newIndexer()
+	c := x.count(n)
+	if i < 0 || j < i || (c >= 0 && j > c) {
+		panic("syntax: slice bounds out of range")
+	}
+	if i == j {
+		return nil
+	}
+	return x.slice(n, i, j)
+}
@@ -0,0 +1,252 @@
+package syntax
+
+import (
+	"sort"
+	"strings"
+)
+
+// NaturalCompare compares a and b like strings.Compare, except that runs of
+// digits are compared by their numeric value, so "web2" < "web10".
+func NaturalCompare(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if !isDigit(a[i]) || !isDigit(b[j]) {
+			if a[i] != b[j] {
+				if a[i] < b[j] {
+					return -1
+				}
+				return 1
+			}
+			i, j = i+1, j+1
+			continue
+		}
+
+		// compare digit runs without their leading zeros
+		for i < len(a) && a[i] == '0' {
+			i++
+		}
+		for j < len(b) && b[j] == '0' {
+			j++
+		}
+		si, sj := i, j
+		for i < len(a) && isDigit(a[i]) {
+			i++
+		}
+		for j < len(b) && isDigit(b[j]) {
+			j++
+		}
+		if i-si != j-sj {
+			if i-si < j-sj {
+				return -1
+			}
+			return 1
+		}
+		if c := strings.Compare(a[si:i], b[sj:j]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(a)-i < len(b)-j:
+		return -1
+	case len(a)-i > len(b)-j:
+		return 1
+	}
+	// equal by value, e.g. "01" and "1"
+	return strings.Compare(a, b)
+}
+
+func compareFunc(flags ExpandFlags) func(a, b string) int {
+	if flags&NaturalSorted != 0 {
+		return NaturalCompare
+	}
+	return strings.Compare
+}
+
+// edgeDigits reports whether strings produced by exp may start or end with
+// a digit.
+func edgeDigits(exp *BraceExp, flags ExpandFlags) (start, end bool) {
+	switch exp.Op {
+	case OpIntegerRange:
+		return true, true
+	case OpCharRange:
+		sta, num, sep, _ := rangeData(exp)
+		lo, hi := sta, sta+num*sep
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		digits := lo <= '9' && hi >= '0'
+		return digits, digits
+	case OpConcat:
+		for _, sub := range exp.Subs {
+			if min, _ := lenBounds(sub, flags); min == 0 {
+				return true, true
+			}
+		}
+		start, _ = edgeDigits(exp.Subs[0], flags)
+		_, end = edgeDigits(exp.Subs[len(exp.Subs)-1], flags)
+		return start, end
+	case OpAlternate:
+		for _, sub := range exp.Subs {
+			s, e := edgeDigits(sub, flags)
+			start, end = start || s, end || e
+		}
+		return start, end
+	default:
+		val := appendConst(nil, exp, flags)
+		if len(val) == 0 {
+			return true, true
+		}
+		return isDigit(val[0]), isDigit(val[len(val)-1])
+	}
+}
+
+// precedes reports whether every string of a sorts before every string of
+// b, judging by their literal heads.
+func precedes(a, b *BraceExp, flags ExpandFlags) bool {
+	ha, ca := head(a, flags)
+	hb, cb := head(b, flags)
+	if ca && cb {
+		return compareFunc(flags)(string(ha), string(hb)) < 0
+	}
+	for p := 0; p < len(ha) && p < len(hb); p++ {
+		if ha[p] == hb[p] {
+			continue
+		}
+		if flags&NaturalSorted != 0 && (isDigit(ha[p]) || isDigit(hb[p])) {
+			return false
+		}
+		return ha[p] < hb[p]
+	}
+	// a is exactly a prefix of every string of b
+	return ca && len(ha) < len(hb)
+}
+
+// isSorted reports whether exp can be proven to produce strictly ascending
+// strings in the order selected by flags.
+func isSorted(exp *BraceExp, flags ExpandFlags) bool {
+	natural := flags&NaturalSorted != 0
+	switch exp.Op {
+	case OpIntegerRange:
+		sta, num, sep, _ := rangeData(exp)
+		if num == 0 {
+			return true
+		}
+		if sep < 0 || sta < 0 {
+			return false
+		}
+		if rangeFormat(exp)&fmtHex != 0 {
+			// hex letters sort after digits, but not in natural order
+			return !natural && fixedLen(exp, flags)
+		}
+		return natural || fixedLen(exp, flags)
+	case OpCharRange:
+		_, num, sep, _ := rangeData(exp)
+		return num == 0 || sep > 0
+	case OpConcat:
+		subs := exp.Subs
+		for i, sub := range subs {
+			if !isSorted(sub, flags) {
+				return false
+			}
+			if i == len(subs)-1 {
+				break
+			}
+			if natural {
+				if _, end := edgeDigits(sub, flags); end {
+					if start, _ := edgeDigits(subs[i+1], flags); start {
+						return false
+					}
+				}
+				if sub.Op == OpIntegerRange {
+					continue
+				}
+			}
+			if !fixedLen(sub, flags) {
+				return false
+			}
+		}
+		return true
+	case OpAlternate:
+		for i, sub := range exp.Subs {
+			if !isSorted(sub, flags) {
+				return false
+			}
+			if i > 0 && !precedes(exp.Subs[i-1], sub, flags) {
+				return false
+			}
+		}
+		return true
+	default:
+		return true
+	}
+}
+
+// sortTree returns a copy of exp with descending ranges turned around and
+// the items of alternates ordered by their heads, which is often enough to
+// make the expansion sorted without collecting it.
+func sortTree(exp *BraceExp, flags ExpandFlags) *BraceExp {
+	switch exp.Op {
+	case OpConcat:
+		subs := make([]*BraceExp, len(exp.Subs))
+		for i, sub := range exp.Subs {
+			subs[i] = sortTree(sub, flags)
+		}
+		return newConcat(subs...)
+	case OpAlternate:
+		subs := make([]*BraceExp, len(exp.Subs))
+		for i, sub := range exp.Subs {
+			subs[i] = sortTree(sub, flags)
+		}
+		compare := compareFunc(flags)
+		heads := make(map[*BraceExp]string, len(subs))
+		for _, sub := range subs {
+			h, _ := head(sub, flags)
+			heads[sub] = string(h)
+		}
+		sort.SliceStable(subs, func(i, j int) bool {
+			return compare(heads[subs[i]], heads[subs[j]]) < 0
+		})
+		return &BraceExp{Op: OpAlternate, Subs: subs}
+	case OpIntegerRange, OpCharRange:
+		sta, num, sep, wid := rangeData(exp)
+		if sep < 0 {
+			sta, sep = sta+num*sep, -sep
+		}
+		return newRange(exp.Op, sta, num, sep, wid, rangeFormat(exp))
+	default:
+		return clone(exp)
+	}
+}
+
+// sortedExp returns a tree producing the expansion of exp in sorted order,
+// or false if the expansion has to be collected and sorted.
+func sortedExp(exp *BraceExp, flags ExpandFlags) (*BraceExp, bool) {
+	if isSorted(exp, flags) {
+		return exp, true
+	}
+	if sorted := sortTree(exp, flags); isSorted(sorted, flags) {
+		return sorted, true
+	}
+	return exp, false
+}
+
+func walkSorted(exp *BraceExp, flags ExpandFlags, handler WalkBytesHandler, buffer []byte, tick func()) []byte {
+	var data []string
+	var collect WalkBytesHandler = func(b []byte) { data = append(data, string(b)) }
+	if tick != nil {
+		collect = tickHandler(collect, tick)
+	}
+	buffer = walk(exp, flags, collect, buffer)
+
+	compare := compareFunc(flags)
+	sort.SliceStable(data, func(i, j int) bool {
+		return compare(data[i], data[j]) < 0
+	})
+	for i, str := range data {
+		if flags&Unique != 0 && i > 0 && str == data[i-1] {
+			continue
+		}
+		handler(append(buffer[:0], str...))
+	}
+	return buffer
+}
@@ -0,0 +1,120 @@
+package syntax
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrDuplicateToken = errors.New("syntax: duplicate token")
+	ErrShadowedToken  = errors.New("syntax: token starts with an escape or a quote")
+)
+
+// Syntax sets the tokens of brace expressions. A token may be several
+// bytes long, and an empty token keeps its default. Escapes and quotes take
+// precedence over the tokens.
+type Syntax struct {
+	Open      string // starts a brace expression, "{" by default
+	Close     string // ends a brace expression, "}" by default
+	Separator string // separates the items of an alternate, "," by default
+	Range     string // separates the bounds and the step of a range, ".." by default
+}
+
+var DefaultSyntax = Syntax{Open: "{", Close: "}", Separator: ",", Range: ".."}
+
+type tokenKind uint8
+
+const (
+	tokNone tokenKind = iota
+	tokOpen
+	tokClose
+	tokSeparator
+	tokRange
+)
+
+func (s Syntax) withDefaults() Syntax {
+	if s.Open == "" {
+		s.Open = DefaultSyntax.Open
+	}
+	if s.Close == "" {
+		s.Close = DefaultSyntax.Close
+	}
+	if s.Separator == "" {
+		s.Separator = DefaultSyntax.Separator
+	}
+	if s.Range == "" {
+		s.Range = DefaultSyntax.Range
+	}
+	return s
+}
+
+// Validate reports whether the tokens of s, defaults included, can all be
+// told apart: no two tokens may be the same, and none may start with a
+// backslash or a quote, which take precedence over the tokens.
+func (s Syntax) Validate() error {
+	s = s.withDefaults()
+	toks := [...]string{s.Open, s.Close, s.Separator, s.Range}
+	for i, tok := range toks {
+		switch tok[0] {
+		case '\\', '"', '\'':
+			return ErrShadowedToken
+		}
+		for _, other := range toks[:i] {
+			if tok == other {
+				return ErrDuplicateToken
+			}
+		}
+	}
+	return nil
+}
+
+// tokens is a custom syntax prepared for parsing. first marks the bytes a
+// token starts with, so that other bytes are never compared with the
+// tokens.
+type tokens struct {
+	Syntax
+	first [256]bool
+}
+
+// newTokens returns the tokens of s, or nil when s is the default syntax,
+// which the parser reads byte by byte.
+func newTokens(s Syntax) *tokens {
+	if s = s.withDefaults(); s == DefaultSyntax {
+		return nil
+	}
+	t := &tokens{Syntax: s}
+	for _, tok := range [...]string{s.Open, s.Close, s.Separator, s.Range} {
+		t.first[tok[0]] = true
+	}
+	return t
+}
+
+// token returns the longest token input starts with.
+func (t *tokens) token(input string) (kind tokenKind, tok string) {
+	if !t.first[input[0]] {
+		return tokNone, ""
+	}
+	for i, s := range [...]string{t.Open, t.Close, t.Separator, t.Range} {
+		if len(s) > len(tok) && strings.HasPrefix(input, s) {
+			kind, tok = tokenKind(i+1), s
+		}
+	}
+	return kind, tok
+}
+
+// defaultToken is token for the default syntax.
+func defaultToken(input string) (kind tokenKind, tok string) {
+	switch input[0] {
+	case '{':
+		return tokOpen, "{"
+	case '}':
+		return tokClose, "}"
+	case ',':
+		return tokSeparator, ","
+	case '.':
+		if len(input) > 1 && input[1] == '.' {
+			return tokRange, ".."
+		}
+	}
+	return tokNone, ""
+}
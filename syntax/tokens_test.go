@@ -0,0 +1,89 @@
+package syntax_test
+
+import (
+	"testing"
+
+	"github.com/pierre-primary/go-braces/syntax"
+)
+
+func TestSyntax(t *testing.T) {
+	brackets := DefineSyntaxExpand(t, syntax.Syntax{Open: "[", Close: "]", Range: "-"}, 0)
+	brackets("a[b,c]d", E{"abd", "acd"})
+	brackets("f[1-3]", E{"f1", "f2", "f3"})
+	brackets("[1-7-3]", E{"1", "4", "7"})
+	brackets("[-2--1]", E{"-2", "-1"})
+	brackets("[a-c,x]{1,2}", E{"a-c{1,2}", "x{1,2}"})
+	brackets("[a]", E{"[a]"})
+
+	angles := DefineSyntaxExpand(t, syntax.Syntax{Open: "<<", Close: ">>", Separator: "|"}, 0)
+	angles("x<<a|b<<1..2>>>>", E{"xa", "xb1", "xb2"})
+	angles("<<a>>>", E{"<<a>>>"})
+	angles("<<<<a>>|b>>", E{"<<a>>", "b"})
+	angles("<a|b>", E{"<a|b>"})
+	angles(`\<<a|b>>`, E{"<<a|b>>"})
+	angles(`\<<<a|b>>`, E{"<a", "<b"})
+	angles("<<a,b|c>>", E{"a,b", "c"})
+
+	// empty tokens keep their defaults
+	DefineSyntaxExpand(t, syntax.Syntax{}, 0)("{a,b}{1..2}", E{"a1", "a2", "b1", "b2"})
+}
+
+func TestDefaultSyntax(t *testing.T) {
+	// the default tokens parse exactly like Parse, the ".." edge cases
+	// included
+	for _, c := range []struct {
+		input    string
+		flags    syntax.ParseFlags
+		pattern  string
+		expected []string
+	}{
+		{"x{a,b}{1..2}y", 0, "x{a,b}{1..2}y", E{"xa1y", "xa2y", "xb1y", "xb2y"}},
+		{"{1..7..3}", 0, "{1..7..3}", E{"1", "4", "7"}},
+		{"{a..c}", 0, "{a..c}", E{"a", "b", "c"}},
+		{"{1..}", 0, `\{1..\}`, E{"{1..}"}},
+		{"{..1}", 0, `\{..1\}`, E{"{..1}"}},
+		{"{1...3}", 0, `\{1...3\}`, E{"{1...3}"}},
+		{"{a.b,c}", 0, "{a.b,c}", E{"a.b", "c"}},
+		{"{1..2..}", 0, `\{1..2..\}`, E{"{1..2..}"}},
+		{"a{}b}", 0, `a\{\}b\}`, E{"a{}b}"}},
+		{"{a}}", 0, `\{a\}\}`, E{"{a}}"}},
+		{"{...2}", syntax.AnyCharRange, `\{...2\}`, E{"{...2}"}},
+		{"{....}}", syntax.AnyCharRange, `\{....\}\}`, E{"{....}}"}},
+		{"b.{....}b", syntax.AnyCharRange, `b.\{....\}b`, E{"b.{....}b"}},
+		{`{...\.}x`, syntax.AnyCharRange, `\{...\.\}x`, E{"{....}x"}},
+		{"Zb}${...x}", syntax.ZshDialect, `Zb\}${.,x}`, E{"Zb}$.", "Zb}$x"}},
+		{"{....}x", syntax.ZshDialect, ".x", E{".x"}},
+	} {
+		for _, s := range []syntax.Syntax{syntax.DefaultSyntax, {}} {
+			DefineSyntaxExpand(t, s, c.flags)(c.input, c.expected)
+			exp, _ := syntax.ParseWithSyntax(c.input, s, c.flags)
+			if str := exp.String(); str != c.pattern {
+				t.Fatal(c.input, str)
+			}
+		}
+		DefineParseExpand(t, c.flags)(c.input, c.expected)
+	}
+}
+
+func TestSyntaxValidate(t *testing.T) {
+	for _, c := range []struct {
+		s   syntax.Syntax
+		err error
+	}{
+		{syntax.Syntax{}, nil},
+		{syntax.Syntax{Open: "<<", Close: ">>", Separator: "|"}, nil},
+		{syntax.Syntax{Open: "<", Close: "<<"}, nil},
+		{syntax.Syntax{Open: "|", Close: "|"}, syntax.ErrDuplicateToken},
+		{syntax.Syntax{Separator: ".."}, syntax.ErrDuplicateToken},
+		{syntax.Syntax{Open: `\(`, Close: `\)`}, syntax.ErrShadowedToken},
+		{syntax.Syntax{Separator: `"`}, syntax.ErrShadowedToken},
+		{syntax.Syntax{Range: "'-"}, syntax.ErrShadowedToken},
+	} {
+		if err := c.s.Validate(); err != c.err {
+			t.Fatal(c.s, err)
+		}
+		if _, err := syntax.ParseWithSyntax("|a,b|", c.s); err != c.err {
+			t.Fatal(c.s, err)
+		}
+	}
+}
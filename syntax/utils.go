@@ -111,3 +111,206 @@ func appendNumber(buf []byte, num int, align int) []byte {
 	}
 	return append(buf, a[i:]...)
 }
+
+// isZeroPadded reports whether val has leading zeros, like "05" or "-005".
+func isZeroPadded(val []byte) bool {
+	if len(val) > 0 && val[0] == '-' {
+		val = val[1:]
+	}
+	return len(val) >= 2 && val[0] == '0' && isDigit(val[1])
+}
+
+// bracketEnd returns the index of the bracket closing the one at sta, or
+// the last index of input if it is not closed. Brackets nest, and a
+// backslash skips the next character.
+func bracketEnd(input string, sta int) int {
+	depth := 0
+	for i := sta; i < len(input); i++ {
+		switch input[i] {
+		case '[':
+			depth++
+		case '\\':
+			i++
+		case ']':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(input) - 1
+}
+
+// numFormat tells how the numbers of an integer range are written, besides
+// their padding: the base, the case of hex digits and the prefix.
+type numFormat int
+
+const (
+	fmtHex         numFormat = 1 << iota // base 16
+	fmtOctal                             // base 8
+	fmtUpper                             // upper-case hex digits
+	fmtPrefix                            // a 0x or 0o prefix
+	fmtUpperPrefix                       // a 0X or 0O prefix
+)
+
+// valid reports whether f is a format the parsers build: a prefix needs a
+// base, octal needs its prefix and upper-case digits need hex.
+func (f numFormat) valid() bool {
+	switch {
+	case f&^(fmtHex|fmtOctal|fmtUpper|fmtPrefix|fmtUpperPrefix) != 0,
+		f&fmtHex != 0 && f&fmtOctal != 0,
+		f&fmtUpper != 0 && f&fmtHex == 0,
+		f&fmtUpperPrefix != 0 && f&fmtPrefix == 0,
+		f&fmtPrefix != 0 && f&(fmtHex|fmtOctal) == 0,
+		f&fmtOctal != 0 && f&fmtPrefix == 0:
+		return false
+	}
+	return true
+}
+
+func (f numFormat) base() uint {
+	switch {
+	case f&fmtHex != 0:
+		return 16
+	case f&fmtOctal != 0:
+		return 8
+	}
+	return 10
+}
+
+func (f numFormat) prefix() string {
+	if f&fmtPrefix == 0 {
+		return ""
+	}
+	p := "0xX"
+	if f&fmtOctal != 0 {
+		p = "0oO"
+	}
+	if f&fmtUpperPrefix != 0 {
+		return p[:1] + p[2:]
+	}
+	return p[:2]
+}
+
+// appendInt appends num written in the format f, padded with zeros after
+// the sign and the prefix to align bytes.
+func appendInt(buf []byte, num int, align int, f numFormat) []byte {
+	if f == 0 {
+		return appendNumber(buf, num, align)
+	}
+	digits := "0123456789abcdef"
+	if f&fmtUpper != 0 {
+		digits = "0123456789ABCDEF"
+	}
+	base := f.base()
+	u := absToUint(num)
+
+	var a [64]byte
+	i := len(a)
+	for u >= base {
+		i--
+		a[i] = digits[u%base]
+		u /= base
+	}
+	i--
+	a[i] = digits[u]
+
+	if num < 0 {
+		align--
+		buf = append(buf, '-')
+	}
+	prefix := f.prefix()
+	buf = append(buf, prefix...)
+	if align -= len(prefix) + len(a[i:]); align > 0 {
+		buf = appendZero(buf, align)
+	}
+	return append(buf, a[i:]...)
+}
+
+// parseRadixInt parses a hexadecimal or octal integer: 0x1f, -0O17, or bare
+// hex digits when hex is set. It reports the format of val, whether its
+// digits are zero-padded and whether it holds lower-case hex digits.
+func parseRadixInt(val []byte, hex bool) (ok bool, num int, f numFormat, padded, lower bool) {
+	neg := false
+	if len(val) > 1 && val[0] == '-' {
+		neg, val = true, val[1:]
+	}
+	switch {
+	case len(val) > 2 && val[0] == '0' && (val[1] == 'x' || val[1] == 'X'):
+		f = fmtHex | fmtPrefix
+	case len(val) > 2 && val[0] == '0' && (val[1] == 'o' || val[1] == 'O'):
+		f = fmtOctal | fmtPrefix
+	case hex && len(val) > 0:
+		f = fmtHex
+	default:
+		return false, 0, 0, false, false
+	}
+	if f&fmtPrefix != 0 {
+		if isUpperCase(val[1]) {
+			f |= fmtUpperPrefix
+		}
+		val = val[2:]
+	}
+
+	ok, num, upper, lower := parseDigits(val, f.base(), neg)
+	if !ok || upper && lower {
+		return false, 0, 0, false, false
+	}
+	if upper {
+		f |= fmtUpper
+	}
+	return true, num, f, len(val) >= 2 && val[0] == '0', lower
+}
+
+// parseDigits parses the digits of an integer in base, negated if neg is
+// set. It reports whether hex digits of either case were seen.
+func parseDigits(val []byte, base uint, neg bool) (ok bool, num int, upper, lower bool) {
+	maxVal := uint(MaxInt)
+	if neg {
+		maxVal++
+	}
+	u := uint(0)
+	for _, b := range val {
+		var d uint
+		switch {
+		case isDigit(b):
+			d = uint(b - '0')
+		case 'a' <= b && b <= 'f':
+			d, lower = uint(b-'a'+10), true
+		case 'A' <= b && b <= 'F':
+			d, upper = uint(b-'A'+10), true
+		default:
+			return false, 0, false, false
+		}
+		if d >= base || u > (maxVal-d)/base {
+			return false, 0, false, false
+		}
+		u = u*base + d
+	}
+	num = int(u)
+	if neg {
+		num = ^int(u - 1)
+	}
+	return len(val) > 0, num, upper, lower
+}
+
+// parseStep parses the step of an integer range whose bounds have the
+// format f. The step is written in the base of the bounds, with or without
+// their prefix.
+func parseStep(val []byte, f numFormat) (ok bool, num int) {
+	if f == 0 {
+		return parseInt(val)
+	}
+	neg := len(val) > 1 && val[0] == '-'
+	if neg {
+		val = val[1:]
+	}
+	x := byte('x')
+	if f&fmtOctal != 0 {
+		x = 'o'
+	}
+	if len(val) > 2 && val[0] == '0' && val[1]|0x20 == x {
+		val = val[2:]
+	}
+	ok, num, _, _ = parseDigits(val, f.base(), neg)
+	return ok, num
+}
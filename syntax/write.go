@@ -0,0 +1,93 @@
+package syntax
+
+import (
+	"bufio"
+	"io"
+)
+
+// WriteOptions controls how WriteToWithOptions lays out the strings.
+type WriteOptions struct {
+	// Sep is written after every string. A nil Sep means a newline; use
+	// []byte{0} for NUL separated output.
+	Sep []byte
+	// ShellQuote single-quotes strings that a POSIX shell would otherwise
+	// split or expand.
+	ShellQuote bool
+}
+
+func isShellSafe(b byte) bool {
+	switch {
+	case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9':
+		return true
+	}
+	switch b {
+	case '_', '@', '%', '+', '=', ':', ',', '.', '/', '-':
+		return true
+	}
+	return false
+}
+
+func appendShellQuote(buf []byte, b []byte) []byte {
+	safe := len(b) > 0
+	for _, c := range b {
+		if !isShellSafe(c) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return append(buf, b...)
+	}
+	buf = append(buf, '\'')
+	for _, c := range b {
+		if c == '\'' {
+			buf = append(buf, `'\''`...)
+		} else {
+			buf = append(buf, c)
+		}
+	}
+	return append(buf, '\'')
+}
+
+// WriteToWithOptions streams the expansion of n to w, without building
+// strings or collecting them, unless sorting is requested. Writes are
+// buffered unless w already is a *bufio.Writer. It returns the number of
+// bytes written and stops at the first write error.
+func (n *BraceExp) WriteToWithOptions(w io.Writer, opts WriteOptions, flags ...ExpandFlags) (written int64, err error) {
+	var flag ExpandFlags
+	for _, f := range flags {
+		flag |= f
+	}
+	sep := opts.Sep
+	if sep == nil {
+		sep = []byte{'\n'}
+	}
+	bw, ok := w.(*bufio.Writer)
+	if !ok {
+		bw = bufio.NewWriter(w)
+	}
+
+	defer recoverAbort(&err)
+	var quoted []byte
+	write := func(b []byte) {
+		nw, err := bw.Write(b)
+		written += int64(nw)
+		if err != nil {
+			panic(walkAbort{err})
+		}
+	}
+	walkExp(n, flag, func(b []byte) {
+		if opts.ShellQuote {
+			quoted = appendShellQuote(quoted[:0], b)
+			b = quoted
+		}
+		write(b)
+		write(sep)
+	}, nil, nil)
+	return written, bw.Flush()
+}
+
+// WriteTo writes the expansion of n to w, one string per line.
+func (n *BraceExp) WriteTo(w io.Writer) (int64, error) {
+	return n.WriteToWithOptions(w, WriteOptions{})
+}
@@ -0,0 +1,42 @@
+package syntax_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/pierre-primary/go-braces/syntax"
+)
+
+type failWriter struct{ n int }
+
+func (w *failWriter) Write(p []byte) (int, error) {
+	if w.n -= len(p); w.n < 0 {
+		return 0, errors.New("full")
+	}
+	return len(p), nil
+}
+
+func TestWriteTo(t *testing.T) {
+	write := func(input string, opts syntax.WriteOptions, expected string, flags ...syntax.ExpandFlags) {
+		exp, err := syntax.Parse(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		n, err := exp.WriteToWithOptions(&buf, opts, flags...)
+		if err != nil || n != int64(buf.Len()) || buf.String() != expected {
+			t.Fatalf("%q %d %v", buf.String(), n, err)
+		}
+	}
+
+	write("a{1..3}", syntax.WriteOptions{}, "a1\na2\na3\n")
+	write("a{1..3}", syntax.WriteOptions{Sep: []byte{0}}, "a1\x00a2\x00a3\x00")
+	write("{b,a}", syntax.WriteOptions{Sep: []byte(", ")}, "a, b, ", Sorted)
+	write(`{,a b,it\'s,x-1}`, syntax.WriteOptions{ShellQuote: true}, "''\n'a b'\n'it'\\''s'\nx-1\n")
+
+	exp, _ := syntax.Parse("{1..100000}")
+	if _, err := exp.WriteTo(&failWriter{n: 10000}); err == nil || err.Error() != "full" {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,74 @@
+package syntax
+
+import "unicode/utf8"
+
+// charClass turns the braces at offset into the characters they list, like
+// zsh with BRACE_CCL set: "a-z" stands for a range, while a dash between
+// bounds out of order is listed as a character. The characters are sorted
+// and deduplicated. Only ASCII is handled, as in zsh.
+func (p *Parser) charClass(offset int) bool {
+	set := p.stack[offset:]
+	var text []byte
+	var escaped []bool
+	for _, exp := range set[1:] {
+		switch exp.Op {
+		case OpLiteral, opBraceRange:
+			text = append(text, exp.Val...)
+			for range exp.Val {
+				escaped = append(escaped, false)
+			}
+		case OpEscape:
+			text = append(text, exp.Val[1:]...)
+			for range exp.Val[1:] {
+				escaped = append(escaped, true)
+			}
+		default:
+			return false
+		}
+	}
+
+	// like xpandbraces, a dash fills the characters from the last one up to
+	// the next one, which is listed on its own
+	var ccl [utf8.RuneSelf]bool
+	last := -1
+	for i, c := range text {
+		if c >= utf8.RuneSelf {
+			return false
+		}
+		if c == '-' && !escaped[i] && last >= 0 && i+1 < len(text) && text[i+1] < utf8.RuneSelf && last <= int(text[i+1]) {
+			for ; last < int(text[i+1]); last++ {
+				ccl[last] = true
+			}
+			last = -1
+			continue
+		}
+		ccl[c] = true
+		last = int(c)
+	}
+
+	var subs []*BraceExp
+	for c := 0; c < len(ccl); c++ {
+		if !ccl[c] {
+			continue
+		}
+		sta := c
+		for c+1 < len(ccl) && ccl[c+1] {
+			c++
+		}
+		if c == sta {
+			subs = append(subs, newLiteral([]byte{byte(c)}))
+		} else {
+			subs = append(subs, newRange(OpCharRange, sta, c-sta, 1, 0, 0))
+		}
+	}
+	if len(subs) == 0 {
+		return false
+	}
+
+	p.stack = p.stack[:offset]
+	for _, exp := range set {
+		p.reuse(exp)
+	}
+	p.push(newAlternate(subs...))
+	return true
+}
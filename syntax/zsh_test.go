@@ -0,0 +1,37 @@
+package syntax_test
+
+import (
+	"testing"
+
+	"github.com/pierre-primary/go-braces/syntax"
+)
+
+func TestZshDialect(t *testing.T) {
+	equal := DefineParseExpand(t, syntax.ZshDialect)
+
+	// BRACE_CCL
+	equal(`{abc}`, E{"a", "b", "c"})
+	equal(`x{cba}y`, E{"xay", "xby", "xcy"})
+	equal(`{abcdef0-9}`, E{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9", "a", "b", "c", "d", "e", "f"})
+	equal(`{a-c0-2}`, E{"0", "1", "2", "a", "b", "c"})
+	equal(`{-a}`, E{"-", "a"})
+	equal(`{a-}`, E{"-", "a"})
+	equal(`{a\-c}`, E{"-", "a", "c"})
+	equal(`{aab}`, E{"a", "b"})
+	equal(`{z-a}`, E{"-", "a", "z"})
+	equal(`{a-c-e}`, E{"a", "b", "c", "d", "e"})
+	equal(`{a-a}`, E{"a"})
+	equal(`{}`, E{"{}"})
+	equal(`{"ab"}`, E{`{ab}`})
+	equal(`{a,b}`, E{"a", "b"})
+
+	// ranges
+	equal(`{1..10..-3}`, E{"10", "7", "4", "1"})
+	equal(`{1..9..-3}`, E{"7", "4", "1"})
+	equal(`{9..1..3}`, E{"9", "6", "3"})
+	equal(`{9..1..-3}`, E{"3", "6", "9"})
+	equal(`{01..100..33}`, E{"001", "034", "067", "100"})
+	equal(`{-1..02}`, E{"-1", "00", "01", "02"})
+	equal(`{a..Z}`, E{"a", "`", "_", "^", "]", `\`, "[", "Z"})
+	equal(`{中..丰}`, E{"中", "丮", "丯", "丰"})
+}